@@ -0,0 +1,302 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/github"
+)
+
+// account is a GitHub account polled by gh-notify, with its own cache directory
+type account struct {
+	name     string // Empty for the implicit account when none are configured
+	hostname string
+	user     string
+	cacheDir string
+}
+
+// accountCache is an account together with its loaded cache
+type accountCache struct {
+	account
+	cache *cache.Cache
+}
+
+// accounts returns the configured accounts, narrowed by --account. Without
+// configured accounts a single unnamed account uses --hostname and the cache
+// directory itself, exactly as before accounts existed.
+func accounts() ([]account, error) {
+	if len(cfg.Accounts) == 0 {
+		if accountName != "" {
+			return nil, fmt.Errorf("unknown account %q: no accounts are configured", accountName)
+		}
+		return []account{{hostname: hostname, cacheDir: cacheDir}}, nil
+	}
+
+	var result []account
+	for _, acct := range cfg.Accounts {
+		if accountName != "" && acct.Name != accountName {
+			continue
+		}
+		result = append(result, account{
+			name:     acct.Name,
+			hostname: acct.Hostname,
+			user:     acct.User,
+			cacheDir: filepath.Join(cacheDir, acct.Name),
+		})
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("unknown account %q", accountName)
+	}
+
+	return result, nil
+}
+
+// label describes the account for user-facing output
+func (a account) label() string {
+	if a.name == "" {
+		return "default account"
+	}
+	return a.name
+}
+
+// newClient creates a GitHub client authenticated as the account
+func (a account) newClient() (*github.Client, error) {
+	client, err := github.NewClient(a.hostname, a.user)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub client for %s: %w", a.label(), err)
+	}
+	return client, nil
+}
+
+// openStore opens the account's cache storage, as set by storage.backend
+func (a account) openStore() (cache.Store, error) {
+	store, err := cache.OpenStore(cfg.Storage.Backend, a.cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache for %s: %w", a.label(), err)
+	}
+	return store, nil
+}
+
+// loadCache loads the account's cache from its storage. A corrupt cache
+// file has been moved aside by Load and is replaced with an empty cache.
+func (a account) loadCache() (*cache.Cache, error) {
+	store, err := a.openStore()
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = store.Close() }()
+
+	c := cache.New(a.cacheDir)
+	if err := store.Load(c); err != nil {
+		if !errors.Is(err, cache.ErrCorrupt) {
+			return nil, fmt.Errorf("failed to load cache for %s: %w", a.label(), err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v; starting %s with an empty cache\n", err, a.label())
+	}
+	c.SetHistoryRetention(cfg.History.Retention)
+	if cfg.Storage.MaxEntries > 0 {
+		c.MaxEntries = cfg.Storage.MaxEntries
+	}
+	return c, nil
+}
+
+// saveCache writes the account's cache to its storage
+func (a account) saveCache(c *cache.Cache) error {
+	store, err := a.openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := store.Save(c); err != nil {
+		return fmt.Errorf("failed to save cache for %s: %w", a.label(), err)
+	}
+	return nil
+}
+
+// query runs a read-only query against the account's storage. A corrupt cache
+// is reported as in loadCache and queries as empty.
+func (a account) query(query func(store cache.Store) error) error {
+	store, err := a.openStore()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	if err := query(store); err != nil {
+		if !errors.Is(err, cache.ErrCorrupt) {
+			return fmt.Errorf("failed to query cache for %s: %w", a.label(), err)
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+	return nil
+}
+
+// lockCache takes the account's cache lock. Hold it from loading the cache
+// until it is saved, so concurrent runs do not overwrite each other.
+func (a account) lockCache() (*cache.FileLock, error) {
+	lock, err := cache.Lock(a.cacheDir, cache.DefaultLockTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock cache for %s: %w", a.label(), err)
+	}
+	return lock, nil
+}
+
+// lockAccounts locks the cache of every account and returns a function that
+// releases them all
+func lockAccounts(accts []account) (func(), error) {
+	var locks []*cache.FileLock
+	unlock := func() {
+		for _, lock := range locks {
+			_ = lock.Unlock()
+		}
+	}
+
+	for _, acct := range accts {
+		lock, err := acct.lockCache()
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		locks = append(locks, lock)
+	}
+
+	return unlock, nil
+}
+
+// save writes the account's cache to its storage
+func (ac accountCache) save() error {
+	return ac.saveCache(ac.cache)
+}
+
+// loadAccountCaches loads the cache of every account
+func loadAccountCaches(accts []account) ([]accountCache, error) {
+	var caches []accountCache
+	for _, acct := range accts {
+		c, err := acct.loadCache()
+		if err != nil {
+			return nil, err
+		}
+		caches = append(caches, accountCache{account: acct, cache: c})
+	}
+	return caches, nil
+}
+
+// mergedNotifications returns the cached notifications of all accounts, each
+// tagged with its account name
+func mergedNotifications(caches []accountCache) []cache.CacheEntry {
+	var merged []cache.CacheEntry
+	for _, ac := range caches {
+		for _, entry := range ac.cache.GetNotifications() {
+			entry.Account = ac.name
+			merged = append(merged, entry)
+		}
+	}
+	return merged
+}
+
+// queryNotifications returns the cached notifications of all accounts that
+// match q, each tagged with its account name, without loading whole caches
+func queryNotifications(accts []account, q cache.Query) ([]cache.CacheEntry, error) {
+	var merged []cache.CacheEntry
+	for _, acct := range accts {
+		err := acct.query(func(store cache.Store) error {
+			entries, err := store.Notifications(q)
+			for _, entry := range entries {
+				entry.Account = acct.name
+				merged = append(merged, entry)
+			}
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
+
+// queryStars returns the cached star events of all accounts starred at or
+// after since
+func queryStars(accts []account, since time.Time) ([]cache.StarEvent, error) {
+	var merged []cache.StarEvent
+	for _, acct := range accts {
+		err := acct.query(func(store cache.Store) error {
+			stars, err := store.Stars(since)
+			merged = append(merged, stars...)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
+
+// cacheFor returns the loaded cache of the named account. Threads that are not
+// cached carry no account, so they only resolve when there is one account.
+func cacheFor(caches []accountCache, name string) (accountCache, error) {
+	for _, ac := range caches {
+		if ac.name == name {
+			return ac, nil
+		}
+	}
+	if name == "" && len(caches) == 1 {
+		return caches[0], nil
+	}
+	return accountCache{}, fmt.Errorf("no account known for this thread; pass --account to choose one")
+}
+
+// applyToThreads runs apply for each thread with its account's client and
+// cache, then saves the caches of accounts where apply succeeded at least
+// once. Failures are printed and counted.
+func applyToThreads(caches []accountCache, threads []cache.CacheEntry, apply func(client *github.Client, c *cache.Cache, thread cache.CacheEntry) error) (int, error) {
+	// Resolve every account first, so an unknown one stops before any change
+	accountCaches := make([]accountCache, len(threads))
+	for i, thread := range threads {
+		ac, err := cacheFor(caches, thread.Account)
+		if err != nil {
+			return 0, fmt.Errorf("thread %s: %w", thread.ID, err)
+		}
+		accountCaches[i] = ac
+	}
+
+	clients := make(map[string]*github.Client)
+	changed := make(map[string]bool)
+	failed := 0
+
+	for i, thread := range threads {
+		ac := accountCaches[i]
+
+		client, ok := clients[ac.name]
+		if !ok {
+			var err error
+			client, err = ac.newClient()
+			if err != nil {
+				return failed, err
+			}
+			clients[ac.name] = client
+		}
+
+		if err := apply(client, ac.cache, thread); err != nil {
+			fmt.Fprintf(os.Stderr, "✗ %v\n", err)
+			failed++
+			continue
+		}
+		changed[ac.name] = true
+	}
+
+	for _, ac := range caches {
+		if changed[ac.name] {
+			if err := ac.save(); err != nil {
+				return failed, err
+			}
+		}
+	}
+
+	return failed, nil
+}
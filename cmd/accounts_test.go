@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/config"
+)
+
+// setAccountsConfig installs a config with the given accounts for one test
+func setAccountsConfig(t *testing.T, accts []config.AccountConfig, name string) {
+	t.Helper()
+
+	cfg = config.Default()
+	cfg.Accounts = accts
+	cacheDir = t.TempDir()
+	accountName = name
+	t.Cleanup(func() {
+		cfg = nil
+		cacheDir = ""
+		accountName = ""
+	})
+}
+
+// TestAccounts_Implicit tests the single unnamed account used without configured accounts
+func TestAccounts_Implicit(t *testing.T) {
+	setAccountsConfig(t, nil, "")
+
+	accts, err := accounts()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(accts) != 1 || accts[0].name != "" || accts[0].cacheDir != cacheDir {
+		t.Errorf("Expected one unnamed account using the cache dir, got %+v", accts)
+	}
+
+	accountName = "work"
+	if _, err := accounts(); err == nil {
+		t.Error("Expected error selecting an account when none are configured")
+	}
+
+	t.Logf("✓ Implicit account test passed!")
+}
+
+// TestAccounts_Configured tests cache namespaces and the --account filter
+func TestAccounts_Configured(t *testing.T) {
+	setAccountsConfig(t, []config.AccountConfig{
+		{Name: "personal"},
+		{Name: "work", User: "me-at-work"},
+		{Name: "ghes", Hostname: "ghe.example.com"},
+	}, "")
+
+	accts, err := accounts()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(accts) != 3 {
+		t.Fatalf("Expected 3 accounts, got %d", len(accts))
+	}
+	if accts[2].cacheDir != filepath.Join(cacheDir, "ghes") || accts[2].hostname != "ghe.example.com" {
+		t.Errorf("Expected ghes account in its own cache dir, got %+v", accts[2])
+	}
+
+	accountName = "work"
+	accts, err = accounts()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(accts) != 1 || accts[0].user != "me-at-work" {
+		t.Errorf("Expected only the work account, got %+v", accts)
+	}
+
+	accountName = "missing"
+	if _, err := accounts(); err == nil {
+		t.Error("Expected error for an unknown account")
+	}
+
+	t.Logf("✓ Configured accounts test passed!")
+}
+
+// TestMergedNotifications tests merging caches and tagging entries with their account
+func TestMergedNotifications(t *testing.T) {
+	personal := cache.New("")
+	personal.AddNotifications([]cache.CacheEntry{{ID: "1"}})
+	work := cache.New("")
+	work.AddNotifications([]cache.CacheEntry{{ID: "2"}, {ID: "3"}})
+
+	caches := []accountCache{
+		{account: account{name: "personal"}, cache: personal},
+		{account: account{name: "work"}, cache: work},
+	}
+
+	merged := mergedNotifications(caches)
+
+	if len(merged) != 3 {
+		t.Fatalf("Expected 3 merged notifications, got %d", len(merged))
+	}
+	if merged[0].Account != "personal" || merged[2].Account != "work" {
+		t.Errorf("Expected entries tagged with their account, got %+v", merged)
+	}
+
+	// Uncached threads carry no account, which is ambiguous with two accounts
+	if _, err := cacheFor(caches, ""); err == nil || !strings.Contains(err.Error(), "--account") {
+		t.Errorf("Expected an error asking for --account, got %v", err)
+	}
+	if got, err := cacheFor(caches[:1], ""); err != nil || got.name != "personal" {
+		t.Errorf("Expected the only account, got %q (%v)", got.name, err)
+	}
+	if got, err := cacheFor(caches, "work"); err != nil || got.name != "work" {
+		t.Errorf("Expected the work account, got %q (%v)", got.name, err)
+	}
+
+	t.Logf("✓ Merged notifications test passed!")
+}
+
+// TestQueryNotifications tests querying every account's storage and tagging entries
+func TestQueryNotifications(t *testing.T) {
+	setAccountsConfig(t, []config.AccountConfig{{Name: "personal"}, {Name: "work"}}, "")
+	cfg.Storage.Backend = cache.BackendSQLite
+
+	accts, err := accounts()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	now := time.Now().UTC()
+	for i, acct := range accts {
+		c, err := acct.loadCache()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		c.AddNotifications([]cache.CacheEntry{
+			{ID: acct.name, Repository: "owner/repo", Reason: "mention", Timestamp: now, UpdatedAt: now.Add(-time.Duration(i) * time.Hour)},
+			{ID: acct.name + "-other", Repository: "owner/tool", Reason: "mention", Timestamp: now, UpdatedAt: now},
+		})
+		if err := acct.saveCache(c); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	entries, err := queryNotifications(accts, cache.Query{Repository: "REPO"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(entries) != 2 || entries[0].Account != "personal" || entries[1].ID != "work" || entries[1].Account != "work" {
+		t.Errorf("Expected one tagged entry per account, got %+v", entries)
+	}
+
+	t.Logf("✓ Notification query test passed!")
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/github"
+	"github.com/bnema/gh-notify/internal/logger"
+	"github.com/bnema/gh-notify/internal/notifier"
+)
+
+// The desktop notification backend is shared by all accounts and chosen once
+var (
+	backendOnce    sync.Once
+	desktopBackend notifier.Backend
+	backendErr     error
+)
+
+// notificationBackend returns the configured notification backend. Only
+// "auto" falls back to notify-send; a backend chosen explicitly that is
+// unavailable is an error.
+func notificationBackend() (notifier.Backend, error) {
+	backendOnce.Do(func() {
+		desktopBackend, backendErr = notifier.NewBackend(cfg.Notifier.Backend)
+		if backendErr != nil {
+			backendErr = fmt.Errorf("notification backend %q unavailable: %w", cfg.Notifier.Backend, backendErr)
+		}
+	})
+	return desktopBackend, backendErr
+}
+
+// newNotifier returns a desktop notifier for acct whose buttons are waited
+// for and run by d
+func newNotifier(acct account, client *github.Client, d notifier.Dispatcher) (*notifier.Notifier, error) {
+	n := notifier.New(true)
+	n.SetUrgency(cfg.Notifier.Urgency)
+	n.SetHost(client.Host())
+	n.SetAccount(acct.name)
+	n.SetDispatcher(d)
+	n.SetTemplates(notificationTemplates)
+	// The summary is shown directly, so it shares the dispatcher's backend. An
+	// explicit D-Bus backend is checked here too, so sync reports it missing
+	// instead of only its detached helpers.
+	if cfg.Notifier.Summary || cfg.Notifier.Backend == notifier.BackendDBus {
+		backend, err := notificationBackend()
+		if err != nil {
+			return nil, err
+		}
+		n.SetBackend(backend)
+	}
+	return n, nil
+}
+
+// watchDispatcher waits for each notification in its own goroutine and runs
+// the chosen button there. Thread buttons update the cache on disk under the
+// cache lock, like the notify-helper of sync.
+type watchDispatcher struct {
+	backend notifier.Backend
+	threads notifier.ThreadActions
+}
+
+func (d *watchDispatcher) Dispatch(p notifier.Pending) error {
+	go func() {
+		button, err := notifier.Wait(d.backend, p)
+		if err != nil {
+			logger.Warn().Err(err).Msg("Failed to show desktop notification")
+			return
+		}
+		if button == nil {
+			return
+		}
+
+		if err := button.Run(d.threads); err != nil {
+			logger.Error().Err(err).Str("action", button.Label).Msg("Notification action failed")
+		}
+	}()
+
+	return nil
+}
+
+// threadActions carries out the desktop notification buttons of one account:
+// threads marked read leave the cache, and muted threads are remembered
+type threadActions struct {
+	client  github.GitHubClientInterface
+	cache   *cache.Cache
+	changed func() // Called after the cache changed; may be nil
+}
+
+// MarkRead marks the threads as read on GitHub and removes them from the
+// cache. Threads marked before a failure are still removed.
+func (a *threadActions) MarkRead(entries []cache.CacheEntry) error {
+	var read []string
+	var err error
+	for _, entry := range entries {
+		if err = a.client.MarkThreadRead(entry.ID); err != nil {
+			break
+		}
+		read = append(read, entry.ID)
+	}
+
+	if removed := a.cache.RemoveNotifications(read...); removed > 0 {
+		logger.Info().Int("threads", removed).Msg("Marked threads as read from desktop notification")
+		a.notifyChanged()
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to mark %d of %d threads as read: %w", len(entries)-len(read), len(entries), err)
+	}
+	return nil
+}
+
+// Mute ignores the thread on GitHub and remembers it as muted
+func (a *threadActions) Mute(entry cache.CacheEntry) error {
+	if err := a.client.MuteThread(entry.ID); err != nil {
+		return err
+	}
+
+	a.cache.Mute(entry.ID)
+	logger.Info().Str("thread_id", entry.ID).Msg("Muted thread from desktop notification")
+	a.notifyChanged()
+
+	return nil
+}
+
+func (a *threadActions) notifyChanged() {
+	if a.changed != nil {
+		a.changed()
+	}
+}
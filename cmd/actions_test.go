@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/github"
+	"github.com/bnema/gh-notify/internal/notifier"
+)
+
+// actionsClient records thread actions and fails for failID
+type actionsClient struct {
+	github.GitHubClientInterface
+	failID string
+	read   []string
+	muted  []string
+}
+
+func (a *actionsClient) MarkThreadRead(threadID string) error {
+	if threadID == a.failID {
+		return fmt.Errorf("HTTP 500")
+	}
+	a.read = append(a.read, threadID)
+	return nil
+}
+
+func (a *actionsClient) MuteThread(threadID string) error {
+	a.muted = append(a.muted, threadID)
+	return nil
+}
+
+// TestThreadActions_MarkRead tests that threads marked read from a notification leave the cache
+func TestThreadActions_MarkRead(t *testing.T) {
+	c := cache.New("")
+	c.AddNotifications([]cache.CacheEntry{{ID: "1"}, {ID: "2"}, {ID: "3"}})
+
+	changed := false
+	client := &actionsClient{failID: "3"}
+	actions := &threadActions{client: client, cache: c, changed: func() { changed = true }}
+
+	if err := actions.MarkRead([]cache.CacheEntry{{ID: "1"}}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(c.GetNotifications()) != 2 || !changed {
+		t.Errorf("Expected thread '1' removed and change reported, got %d cached, changed=%v", len(c.GetNotifications()), changed)
+	}
+
+	// A failure keeps the threads that were not marked
+	if err := actions.MarkRead([]cache.CacheEntry{{ID: "2"}, {ID: "3"}}); err == nil {
+		t.Error("Expected error for failing thread")
+	}
+	remaining := c.GetNotifications()
+	if len(remaining) != 1 || remaining[0].ID != "3" {
+		t.Errorf("Expected only thread '3' to stay cached, got %+v", remaining)
+	}
+
+	t.Logf("✓ Thread actions mark read test passed!")
+}
+
+// TestThreadActions_Mute tests that threads muted from a notification are remembered
+func TestThreadActions_Mute(t *testing.T) {
+	c := cache.New("")
+	client := &actionsClient{}
+	actions := &threadActions{client: client, cache: c}
+
+	if err := actions.Mute(cache.CacheEntry{ID: "7"}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(client.muted) != 1 || !c.IsMuted("7") {
+		t.Errorf("Expected thread '7' muted on GitHub and in the cache, got %v", client.muted)
+	}
+
+	t.Logf("✓ Thread actions mute test passed!")
+}
+
+// TestHelperRequest_RoundTrip tests that a helper request keeps the account and thread buttons
+func TestHelperRequest_RoundTrip(t *testing.T) {
+	req := helperRequest{
+		Account: helperAccount{Name: "work", Hostname: "ghe.example.com", CacheDir: "/tmp/gh-notify/work"},
+		Pending: notifier.Pending{
+			Notification: notifier.Notification{Title: "GitHub (work) - org/api", Urgency: "normal"},
+			Buttons: []notifier.Button{
+				{Key: "read", Label: "Mark as read", Kind: notifier.ButtonRead, Entries: []cache.CacheEntry{{ID: "9"}}},
+			},
+		},
+	}
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var decoded helperRequest
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	acct := decoded.Account.account()
+	if acct.name != "work" || acct.hostname != "ghe.example.com" || acct.cacheDir != "/tmp/gh-notify/work" {
+		t.Errorf("Unexpected account: %+v", acct)
+	}
+	if len(decoded.Pending.Buttons) != 1 || decoded.Pending.Buttons[0].Entries[0].ID != "9" {
+		t.Errorf("Unexpected buttons: %+v", decoded.Pending.Buttons)
+	}
+
+	t.Logf("✓ Helper request round trip test passed!")
+}
@@ -6,7 +6,6 @@ import (
 	"os"
 	"strings"
 
-	"github.com/bnema/gh-notify/internal/cache"
 	"github.com/spf13/cobra"
 )
 
@@ -27,13 +26,24 @@ func init() {
 }
 
 func runClear(cmd *cobra.Command, args []string) error {
-	// Load cache to check current state
-	cache := cache.New(cacheDir)
-	if err := cache.Load(cacheDir); err != nil {
-		return fmt.Errorf("failed to load cache: %w", err)
+	// Load caches to check current state
+	accts, err := accounts()
+	if err != nil {
+		return err
 	}
 
-	notifications := cache.GetNotifications()
+	unlock, err := lockAccounts(accts)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	caches, err := loadAccountCaches(accts)
+	if err != nil {
+		return err
+	}
+
+	notifications := mergedNotifications(caches)
 
 	if len(notifications) == 0 {
 		fmt.Println("Cache is already empty.")
@@ -43,27 +53,22 @@ func runClear(cmd *cobra.Command, args []string) error {
 	// Show confirmation unless forced
 	if !force {
 		fmt.Printf("This will clear %d cached notifications.\n", len(notifications))
-		fmt.Print("Are you sure? [y/N]: ")
-
-		reader := bufio.NewReader(os.Stdin)
-		response, err := reader.ReadString('\n')
+		confirmed, err := confirm()
 		if err != nil {
-			return fmt.Errorf("failed to read input: %w", err)
+			return err
 		}
-
-		response = strings.TrimSpace(strings.ToLower(response))
-		if response != "y" && response != "yes" {
+		if !confirmed {
 			fmt.Println("Cancelled.")
 			return nil
 		}
 	}
 
-	// Clear the cache
-	cache.Clear()
-
-	// Save empty cache
-	if err := cache.Save(cacheDir); err != nil {
-		return fmt.Errorf("failed to save cleared cache: %w", err)
+	// Clear and save each cache
+	for _, ac := range caches {
+		ac.cache.Clear()
+		if err := ac.save(); err != nil {
+			return fmt.Errorf("failed to save cleared cache: %w", err)
+		}
 	}
 
 	fmt.Printf("✓ Cache cleared (%d notifications removed)\n", len(notifications))
@@ -74,3 +79,17 @@ func runClear(cmd *cobra.Command, args []string) error {
 
 	return nil
 }
+
+// confirm asks the user to confirm a destructive action on stdin
+func confirm() (bool, error) {
+	fmt.Print("Are you sure? [y/N]: ")
+
+	reader := bufio.NewReader(os.Stdin)
+	response, err := reader.ReadString('\n')
+	if err != nil {
+		return false, fmt.Errorf("failed to read input: %w", err)
+	}
+
+	response = strings.TrimSpace(strings.ToLower(response))
+	return response == "y" || response == "yes", nil
+}
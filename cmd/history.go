@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/catalog"
+	"github.com/bnema/gh-notify/internal/format"
+	"github.com/spf13/cobra"
+)
+
+// history has its own flag variables, as list's init would otherwise reset
+// the shared defaults
+var (
+	historyLimit      int
+	historyRepository string
+	historySince      string
+)
+
+var historyCmd = &cobra.Command{
+	Use:   "history",
+	Short: "Show notification threads seen by sync, including read ones",
+	Long: `Display the history of notification threads seen by sync, newest activity
+first. Unlike list, it keeps threads after they are read: each row shows when
+the thread was first seen and whether it is still unread, was marked as read
+with gh-notify, or disappeared from the unread list (e.g. read on GitHub).
+Reason changes are shown as a trail, e.g. subscribed → review_requested.
+
+Read threads are kept for history.retention (90 days by default).
+
+Examples:
+  gh-notify history                  # Latest threads
+  gh-notify history -r owner/repo    # Threads of one repository
+  gh-notify history --since 7d       # Threads active in the last week`,
+	Args: cobra.NoArgs,
+	RunE: runHistory,
+}
+
+func init() {
+	historyCmd.Flags().IntVarP(&historyLimit, "limit", "l", 50, "maximum number of threads to show (0 for all)")
+	historyCmd.Flags().StringVarP(&historyRepository, "repository", "r", "", "filter by repository name (supports partial matching)")
+	historyCmd.Flags().StringVar(&historySince, "since", "", "only threads active within this age (e.g. 12h, 7d, 2w)")
+}
+
+// historyEntry is a history record with the account it belongs to
+type historyEntry struct {
+	cache.HistoryRecord
+	account string
+}
+
+func runHistory(cmd *cobra.Command, args []string) error {
+	var since time.Time
+	if historySince != "" {
+		age, err := parseAge(historySince)
+		if err != nil {
+			return err
+		}
+		since = time.Now().UTC().Add(-age)
+	}
+
+	accts, err := accounts()
+	if err != nil {
+		return err
+	}
+
+	var entries []historyEntry
+	for _, acct := range accts {
+		err := acct.query(func(store cache.Store) error {
+			records, err := store.History(cache.Query{Repository: historyRepository, Since: since})
+			for _, record := range records {
+				entries = append(entries, historyEntry{HistoryRecord: record, account: acct.name})
+			}
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	entries = filterHistory(entries, historyRepository, since)
+	total := len(entries)
+	if historyLimit > 0 && len(entries) > historyLimit {
+		entries = entries[:historyLimit]
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No history found.")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	defer func() {
+		if err := w.Flush(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error flushing table: %v\n", err)
+		}
+	}()
+
+	// The account column is only shown when accounts are configured
+	showAccount := len(cfg.Accounts) > 0
+	accountHeader, accountSeparator := "", ""
+	if showAccount {
+		accountHeader, accountSeparator = "ACCOUNT\t", "-------\t"
+	}
+
+	if _, err := fmt.Fprintf(w, "%sREPOSITORY\tTYPE\tREASON\tSTATUS\tFIRST SEEN\tTITLE\n", accountHeader); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+	if _, err := fmt.Fprintf(w, "%s----------\t----\t------\t------\t----------\t-----\n", accountSeparator); err != nil {
+		return fmt.Errorf("failed to write header separator: %w", err)
+	}
+
+	now := time.Now().UTC()
+	for _, entry := range entries {
+		accountColumn := ""
+		if showAccount {
+			accountColumn = entry.account + "\t"
+		}
+
+		if _, err := fmt.Fprintf(w, "%s%s\t%s\t%s\t%s\t%s ago\t%s\n",
+			accountColumn,
+			entry.Repository,
+			catalog.Type(entry.Type).Label,
+			reasonTrail(entry.HistoryRecord),
+			historyStatus(entry.HistoryRecord, now),
+			format.Age(now.Sub(entry.FirstSeen)),
+			format.Truncate(entry.Title, 50)); err != nil {
+			return fmt.Errorf("failed to write history row: %w", err)
+		}
+	}
+
+	fmt.Printf("\nShowing %d threads", len(entries))
+	if total > len(entries) {
+		fmt.Printf(" (limited from %d)", total)
+	}
+	fmt.Println()
+
+	return nil
+}
+
+// filterHistory keeps the entries matching the repository filter (partial,
+// like list) that were active since the given time, newest activity first
+func filterHistory(entries []historyEntry, repository string, since time.Time) []historyEntry {
+	var filtered []historyEntry
+	for _, entry := range entries {
+		if repository != "" && !containsIgnoreCase(entry.Repository, repository) {
+			continue
+		}
+		if !since.IsZero() && entry.LastActivity().Before(since) {
+			continue
+		}
+		filtered = append(filtered, entry)
+	}
+
+	sort.SliceStable(filtered, func(i, j int) bool {
+		return filtered[i].LastActivity().After(filtered[j].LastActivity())
+	})
+
+	return filtered
+}
+
+// reasonTrail is the thread's reason, preceded by earlier reasons when it
+// changed, e.g. "subscribed → review_requested"
+func reasonTrail(record cache.HistoryRecord) string {
+	if len(record.ReasonChanges) == 0 {
+		return record.Reason
+	}
+
+	trail := []string{record.ReasonChanges[0].From}
+	for _, change := range record.ReasonChanges {
+		trail = append(trail, change.To)
+	}
+	return strings.Join(trail, " → ")
+}
+
+// historyStatus is "unread", or "read"/"gone" with how long ago, e.g. "read 2h ago"
+func historyStatus(record cache.HistoryRecord, now time.Time) string {
+	status := record.Status()
+	if status == "unread" {
+		return status
+	}
+	return fmt.Sprintf("%s %s ago", status, format.Age(now.Sub(record.ClosedAt())))
+}
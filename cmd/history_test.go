@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"testing"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+)
+
+// TestFilterHistory tests repository and since filters and newest-activity ordering
+func TestFilterHistory(t *testing.T) {
+	now := time.Now().UTC()
+	readAt := now.Add(-time.Hour)
+
+	entries := []historyEntry{
+		{HistoryRecord: cache.HistoryRecord{ID: "old", Repository: "owner/repo", FirstSeen: now.Add(-10 * 24 * time.Hour)}},
+		{HistoryRecord: cache.HistoryRecord{ID: "read", Repository: "owner/repo", FirstSeen: now.Add(-3 * 24 * time.Hour), ReadAt: &readAt}},
+		{HistoryRecord: cache.HistoryRecord{ID: "new", Repository: "owner/repo", FirstSeen: now.Add(-2 * time.Hour)}},
+		{HistoryRecord: cache.HistoryRecord{ID: "other", Repository: "someone/tool", FirstSeen: now}},
+	}
+
+	filtered := filterHistory(entries, "Owner/", now.Add(-7*24*time.Hour))
+
+	var ids []string
+	for _, entry := range filtered {
+		ids = append(ids, entry.ID)
+	}
+	if len(ids) != 2 || ids[0] != "read" || ids[1] != "new" {
+		t.Errorf("Expected [read new], got %v", ids)
+	}
+
+	t.Logf("✓ History filter test passed!")
+}
+
+// TestReasonTrailAndStatus tests history reason trails and status text
+func TestReasonTrailAndStatus(t *testing.T) {
+	now := time.Now().UTC()
+	goneAt := now.Add(-3 * time.Hour)
+
+	record := cache.HistoryRecord{
+		Reason: "author",
+		ReasonChanges: []cache.ReasonChange{
+			{From: "subscribed", To: "review_requested"},
+			{From: "review_requested", To: "author"},
+		},
+		GoneAt: &goneAt,
+	}
+
+	if got := reasonTrail(record); got != "subscribed → review_requested → author" {
+		t.Errorf("Unexpected reason trail: %q", got)
+	}
+	if got := reasonTrail(cache.HistoryRecord{Reason: "mention"}); got != "mention" {
+		t.Errorf("Unexpected reason without changes: %q", got)
+	}
+
+	if got := historyStatus(record, now); got != "gone 3h ago" {
+		t.Errorf("Unexpected status: %q", got)
+	}
+	if got := historyStatus(cache.HistoryRecord{}, now); got != "unread" {
+		t.Errorf("Unexpected status for an unread thread: %q", got)
+	}
+
+	t.Logf("✓ History formatting test passed!")
+}
+
+// TestHistoryFlags_OwnDefaults tests that list's flags do not reset history's defaults
+func TestHistoryFlags_OwnDefaults(t *testing.T) {
+	if historyLimit != 50 {
+		t.Errorf("Expected history limit to default to 50, got %d", historyLimit)
+	}
+	if limit == historyLimit {
+		t.Errorf("Expected list and history limits to be separate, both are %d", limit)
+	}
+
+	t.Logf("✓ History flag defaults test passed!")
+}
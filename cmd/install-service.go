@@ -2,8 +2,10 @@ package cmd
 
 import (
 	"fmt"
+	"path/filepath"
 	"time"
 
+	"github.com/bnema/gh-notify/internal/config"
 	"github.com/bnema/gh-notify/internal/service"
 	"github.com/spf13/cobra"
 )
@@ -24,13 +26,19 @@ The service creates:
 - ~/.config/systemd/user/gh-notify.service
 - ~/.config/systemd/user/gh-notify.timer
 
+The interval defaults to service.interval from the config file. When --config
+(or GH_NOTIFY_CONFIG) is given, the service passes the same file to every sync.
+A host given with --hostname is pinned into the service, so it keeps polling
+that host even if gh's default host changes; a hostname from the config file is
+left to the config, so later edits still apply.
+
 Use --uninstall to remove the service completely.
 Use --dry-run to see what would be installed without making changes.`,
 	RunE: runInstallService,
 }
 
 func init() {
-	installServiceCmd.Flags().DurationVar(&interval, "interval", 60*time.Second, "sync interval (minimum 60s to respect GitHub API guidelines, e.g., 60s, 2m, 5m)")
+	installServiceCmd.Flags().DurationVar(&interval, "interval", config.MinServiceInterval, "sync interval (minimum 60s to respect GitHub API guidelines, e.g., 60s, 2m, 5m)")
 	installServiceCmd.Flags().BoolVar(&uninstall, "uninstall", false, "remove the service instead of installing")
 	installServiceCmd.Flags().BoolVar(&dryRun, "dry-run", false, "show what would be installed without doing it")
 }
@@ -45,12 +53,20 @@ func runInstallService(cmd *cobra.Command, args []string) error {
 		return runUninstall(systemdMgr)
 	}
 
-	return runInstall(systemdMgr)
+	configDefault(cmd.Flags(), "interval", &interval, cfg.Service.Interval)
+
+	// Only a command line host is pinned, as it would override the config
+	var pinnedHostname string
+	if rootCmd.PersistentFlags().Changed("hostname") {
+		pinnedHostname = hostname
+	}
+
+	return runInstall(systemdMgr, pinnedHostname)
 }
 
-func runInstall(systemdMgr *service.SystemdManager) error {
+func runInstall(systemdMgr *service.SystemdManager, pinnedHostname string) error {
 	// Validate minimum interval to respect GitHub API guidelines
-	if interval < 60*time.Second {
+	if interval < config.MinServiceInterval {
 		return fmt.Errorf("interval must be at least 60 seconds to respect GitHub API polling guidelines (X-Poll-Interval header)")
 	}
 
@@ -61,16 +77,26 @@ func runInstall(systemdMgr *service.SystemdManager) error {
 		return nil
 	}
 
+	// Pin an explicitly given config file so the service uses the same settings
+	var configPath string
+	if cfgFile != "" {
+		absPath, err := filepath.Abs(cfgFile)
+		if err != nil {
+			return fmt.Errorf("failed to resolve config path: %w", err)
+		}
+		configPath = absPath
+	}
+
 	if dryRun {
 		fmt.Printf("Installing gh-notify systemd service (interval: %v)\n\n", interval)
-		return systemdMgr.Install(interval, true)
+		return systemdMgr.Install(interval, configPath, pinnedHostname, true)
 	}
 
 	if verbose {
 		fmt.Printf("Installing systemd service with %v interval...\n", interval)
 	}
 
-	if err := systemdMgr.Install(interval, false); err != nil {
+	if err := systemdMgr.Install(interval, configPath, pinnedHostname, false); err != nil {
 		return fmt.Errorf("failed to install service: %w", err)
 	}
 
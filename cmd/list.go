@@ -8,7 +8,10 @@ import (
 	"time"
 
 	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/catalog"
+	"github.com/bnema/gh-notify/internal/format"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
@@ -35,39 +38,19 @@ func init() {
 }
 
 func runList(cmd *cobra.Command, args []string) error {
-	// Load cache
-	c := cache.New(cacheDir)
-	if err := c.Load(cacheDir); err != nil {
-		return fmt.Errorf("failed to load cache: %w", err)
-	}
-
-	notifications := c.GetNotifications()
+	applyListConfig(cmd.Flags())
 
-	// Apply filters
-	if repository != "" {
-		var filtered []cache.CacheEntry
-		for _, notif := range notifications {
-			if containsIgnoreCase(notif.Repository, repository) {
-				filtered = append(filtered, notif)
-			}
-		}
-		notifications = filtered
+	accts, err := accounts()
+	if err != nil {
+		return err
 	}
 
-	if reason != "" {
-		var filtered []cache.CacheEntry
-		for _, notif := range notifications {
-			if notif.Reason == reason {
-				filtered = append(filtered, notif)
-			}
-		}
-		notifications = filtered
+	notifications, err := queryNotifications(accts, listQuery())
+	if err != nil {
+		return err
 	}
-
-	// Sort by UpdatedAt (newest first)
-	sort.Slice(notifications, func(i, j int) bool {
-		return notifications[i].UpdatedAt.After(notifications[j].UpdatedAt)
-	})
+	notifications = filterNotifications(notifications)
+	total := len(notifications)
 
 	// Apply limit
 	if limit > 0 && len(notifications) > limit {
@@ -88,30 +71,45 @@ func runList(cmd *cobra.Command, args []string) error {
 		}
 	}()
 
+	// The account column is only shown when accounts are configured
+	showAccount := len(cfg.Accounts) > 0
+	accountHeader, accountSeparator := "", ""
+	if showAccount {
+		accountHeader, accountSeparator = "ACCOUNT\t", "-------\t"
+	}
+
 	// Header
-	if _, err := fmt.Fprintln(w, "#\tREPOSITORY\tTYPE\tREASON\tAGE\tTITLE\tURL"); err != nil {
+	if _, err := fmt.Fprintf(w, "#\t%sREPOSITORY\tTYPE\tSTATE\tREASON\tAGE\tTITLE\tURL\n", accountHeader); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
-	if _, err := fmt.Fprintln(w, "-\t----------\t----\t------\t---\t-----\t---"); err != nil {
+	if _, err := fmt.Fprintf(w, "-\t%s----------\t----\t-----\t------\t---\t-----\t---\n", accountSeparator); err != nil {
 		return fmt.Errorf("failed to write header separator: %w", err)
 	}
 
 	// Rows
 	now := time.Now().UTC()
 	for i, notif := range notifications {
-		age := formatAge(now.Sub(notif.UpdatedAt))
-		title := truncateString(notif.Title, 40)
-		url := truncateString(notif.WebURL, 50)
-		notifType := notif.Type
-		if notifType == "" {
-			notifType = "Unknown"
+		age := format.Age(now.Sub(notif.UpdatedAt))
+		title := format.Truncate(notif.Title, 40)
+		url := format.Truncate(notif.WebURL, 50)
+		notifType := catalog.Type(notif.Type).Label
+		state := notif.Subject.Label()
+		if state == "" {
+			state = "-"
+		}
+
+		accountColumn := ""
+		if showAccount {
+			accountColumn = notif.Account + "\t"
 		}
 
-		if _, err := fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%s\n",
+		if _, err := fmt.Fprintf(w, "%d\t%s%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
 			i+1,
+			accountColumn,
 			notif.Repository,
 			notifType,
-			notif.Reason,
+			state,
+			catalog.Reason(notif.Reason).Label,
 			age,
 			title,
 			url); err != nil {
@@ -121,33 +119,68 @@ func runList(cmd *cobra.Command, args []string) error {
 
 	// Summary
 	fmt.Printf("\nShowing %d notifications", len(notifications))
-	if limit > 0 && len(c.GetNotifications()) > limit {
-		fmt.Printf(" (limited from %d total)", len(c.GetNotifications()))
+	if total > len(notifications) {
+		fmt.Printf(" (limited from %d total)", total)
 	}
 	fmt.Println()
 
 	return nil
 }
 
-func formatAge(duration time.Duration) string {
-	if duration < time.Minute {
-		return fmt.Sprintf("%ds", int(duration.Seconds()))
-	}
-	if duration < time.Hour {
-		return fmt.Sprintf("%dm", int(duration.Minutes()))
-	}
-	if duration < 24*time.Hour {
-		return fmt.Sprintf("%dh", int(duration.Hours()))
-	}
-	days := int(duration.Hours() / 24)
-	return fmt.Sprintf("%dd", days)
+// applyListConfig fills the list filters from the config file unless they were given as flags
+func applyListConfig(flags *pflag.FlagSet) {
+	configDefault(flags, "limit", &limit, cfg.List.Limit)
+	configDefault(flags, "repository", &repository, cfg.List.Repository)
+	configDefault(flags, "reason", &reason, cfg.List.Reason)
 }
 
-func truncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
+// listQuery selects the notifications matching the repository and reason
+// filters from storage
+func listQuery() cache.Query {
+	return cache.Query{Repository: repository, Reason: reason}
+}
+
+// filterNotifications applies the config rules and the repository and reason
+// filters, and sorts the result newest first. list and open share it so
+// notification numbers match.
+func filterNotifications(notifications []cache.CacheEntry) []cache.CacheEntry {
+	notifications = ruleEngine.Apply(notifications)
+
+	if repository != "" {
+		var filtered []cache.CacheEntry
+		for _, notif := range notifications {
+			if containsIgnoreCase(notif.Repository, repository) {
+				filtered = append(filtered, notif)
+			}
+		}
+		notifications = filtered
+	}
+
+	if reason != "" {
+		var filtered []cache.CacheEntry
+		for _, notif := range notifications {
+			if notif.Reason == reason {
+				filtered = append(filtered, notif)
+			}
+		}
+		notifications = filtered
 	}
-	return s[:maxLen-3] + "..."
+
+	// Sort by UpdatedAt (newest first). Ties are broken by thread ID and
+	// account, so list, read and mute number the threads the same way
+	// whatever order they were loaded in.
+	sort.SliceStable(notifications, func(i, j int) bool {
+		a, b := notifications[i], notifications[j]
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.After(b.UpdatedAt)
+		}
+		if a.ID != b.ID {
+			return a.ID < b.ID
+		}
+		return a.Account < b.Account
+	})
+
+	return notifications
 }
 
 func containsIgnoreCase(s, substr string) bool {
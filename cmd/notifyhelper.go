@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/logger"
+	"github.com/bnema/gh-notify/internal/notifier"
+	"github.com/spf13/cobra"
+)
+
+// helperLogMaxSize is the size past which the helper log starts over
+const helperLogMaxSize = 1 << 20
+
+// notifyHelperCmd shows one notification for sync and runs the button the
+// user chooses, after sync itself has exited
+var notifyHelperCmd = &cobra.Command{
+	Use:    "notify-helper",
+	Short:  "Show a notification and run its chosen action (internal)",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	RunE:   runNotifyHelper,
+}
+
+// helperRequest is what sync sends a notify-helper on stdin
+type helperRequest struct {
+	Account helperAccount    `json:"account"`
+	Pending notifier.Pending `json:"pending"`
+}
+
+// helperAccount carries the account fields a helper needs to update GitHub
+// and the cache
+type helperAccount struct {
+	Name     string `json:"name,omitempty"`
+	Hostname string `json:"hostname,omitempty"`
+	User     string `json:"user,omitempty"`
+	CacheDir string `json:"cache_dir"`
+}
+
+func (a helperAccount) account() account {
+	return account{name: a.Name, hostname: a.Hostname, user: a.User, cacheDir: a.CacheDir}
+}
+
+func runNotifyHelper(cmd *cobra.Command, args []string) error {
+	logger.Init(verbose)
+
+	var req helperRequest
+	if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+		return fmt.Errorf("failed to read notification request: %w", err)
+	}
+
+	// Nobody sees the exit status, so failures are logged to the helper log
+	backend, err := notificationBackend()
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to show desktop notification")
+		return nil
+	}
+	button, err := notifier.Wait(backend, req.Pending)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to show desktop notification")
+		return nil
+	}
+	if button == nil {
+		return nil
+	}
+
+	if err := button.Run(&diskThreadActions{acct: req.Account.account()}); err != nil {
+		logger.Error().Err(err).Str("action", button.Label).Msg("Notification action failed")
+	}
+
+	return nil
+}
+
+// openHelperLog opens the log a detached helper writes its output to, in the
+// account's cache directory. It starts over once it grows past helperLogMaxSize.
+func openHelperLog(cacheDir string) (*os.File, error) {
+	path := filepath.Join(cacheDir, "notify-helper.log")
+
+	flags := os.O_CREATE | os.O_WRONLY | os.O_APPEND
+	if info, err := os.Stat(path); err == nil && info.Size() > helperLogMaxSize {
+		flags |= os.O_TRUNC
+	}
+
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	f, err := os.OpenFile(path, flags, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open notification helper log: %w", err)
+	}
+	return f, nil
+}
+
+// helperDispatcher hands each notification to a detached notify-helper
+// process, so sync returns (and saves its cache) without waiting for the user
+type helperDispatcher struct {
+	acct account
+}
+
+func (d *helperDispatcher) Dispatch(p notifier.Pending) error {
+	payload, err := json.Marshal(helperRequest{
+		Account: helperAccount{
+			Name:     d.acct.name,
+			Hostname: d.acct.hostname,
+			User:     d.acct.user,
+			CacheDir: d.acct.cacheDir,
+		},
+		Pending: p,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode notification request: %w", err)
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to find gh-notify executable: %w", err)
+	}
+
+	helperArgs := []string{"notify-helper"}
+	if cfgFile != "" {
+		helperArgs = append(helperArgs, "--config", cfgFile)
+	}
+
+	helperLog, err := openHelperLog(d.acct.cacheDir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = helperLog.Close() }()
+
+	// The request goes through a pipe written before returning, so it is
+	// complete even though sync may exit before the helper reads it
+	stdin, stdinWriter, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to create pipe: %w", err)
+	}
+	defer func() { _ = stdinWriter.Close() }()
+
+	helper := exec.Command(executable, helperArgs...)
+	helper.Stdin = stdin
+	helper.Stdout = helperLog
+	helper.Stderr = helperLog
+	helper.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	err = helper.Start()
+	_ = stdin.Close()
+	if err != nil {
+		return fmt.Errorf("failed to start notification helper: %w", err)
+	}
+
+	if _, err := stdinWriter.Write(payload); err != nil {
+		return fmt.Errorf("failed to send notification request: %w", err)
+	}
+
+	return helper.Process.Release()
+}
+
+// diskThreadActions runs thread buttons against the account's cache on disk,
+// loading it only once a button is chosen so changes made meanwhile by sync
+// are kept
+type diskThreadActions struct {
+	acct account
+}
+
+func (a *diskThreadActions) MarkRead(entries []cache.CacheEntry) error {
+	return a.update(func(actions *threadActions) error {
+		return actions.MarkRead(entries)
+	})
+}
+
+func (a *diskThreadActions) Mute(entry cache.CacheEntry) error {
+	return a.update(func(actions *threadActions) error {
+		return actions.Mute(entry)
+	})
+}
+
+// update runs apply with a fresh client and cache, saving the cache when
+// apply changed it
+func (a *diskThreadActions) update(apply func(actions *threadActions) error) error {
+	client, err := a.acct.newClient()
+	if err != nil {
+		return err
+	}
+
+	lock, err := a.acct.lockCache()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	c, err := a.acct.loadCache()
+	if err != nil {
+		return err
+	}
+
+	changed := false
+	err = apply(&threadActions{client: client, cache: c, changed: func() { changed = true }})
+	if changed {
+		if saveErr := a.acct.saveCache(c); saveErr != nil {
+			return saveErr
+		}
+	}
+
+	return err
+}
@@ -6,7 +6,6 @@ import (
 	"runtime"
 	"strconv"
 
-	"github.com/bnema/gh-notify/internal/cache"
 	"github.com/spf13/cobra"
 )
 
@@ -18,15 +17,22 @@ var openCmd = &cobra.Command{
 Use 'gh-notify list' to see notification numbers, then use 'gh-notify open N'
 where N is the notification number from the list.
 
+Notification numbers follow the same order and filters as 'gh-notify list',
+including the list defaults from the config file.
+
 Examples:
   gh-notify open 1        # Open the first notification from the list
-  gh-notify open 5        # Open the fifth notification from the list`,
+  gh-notify open 5        # Open the fifth notification from the list
+  gh-notify open -r cli 1 # Open the first notification from 'gh-notify list -r cli'`,
 	Args: cobra.ExactArgs(1),
 	RunE: runOpen,
 }
 
 func init() {
 	rootCmd.AddCommand(openCmd)
+
+	openCmd.Flags().StringVarP(&repository, "repository", "r", "", "filter by repository name (same as list)")
+	openCmd.Flags().StringVar(&reason, "reason", "", "filter by notification reason (same as list)")
 }
 
 func runOpen(cmd *cobra.Command, args []string) error {
@@ -40,13 +46,17 @@ func runOpen(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("notification number must be greater than 0")
 	}
 
-	// Load cache
-	cache := cache.New(cacheDir)
-	if err := cache.Load(cacheDir); err != nil {
-		return fmt.Errorf("failed to load cache: %w", err)
+	accts, err := accounts()
+	if err != nil {
+		return err
 	}
 
-	notifications := cache.GetNotifications()
+	applyListConfig(cmd.Flags())
+	notifications, err := queryNotifications(accts, listQuery())
+	if err != nil {
+		return err
+	}
+	notifications = filterNotifications(notifications)
 	if len(notifications) == 0 {
 		return fmt.Errorf("no notifications found. Run 'gh-notify sync' first")
 	}
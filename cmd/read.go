@@ -0,0 +1,263 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/github"
+	"github.com/spf13/cobra"
+)
+
+var readCmd = &cobra.Command{
+	Use:   "read <n|id:thread-id>... | --all",
+	Short: "Mark notifications as read",
+	Long: `Mark one or more notifications as read on GitHub and remove them from the
+local cache right away, so list and the waybar output update without waiting
+for the next sync.
+
+Arguments are notification numbers from 'gh-notify list', or GitHub thread
+IDs written as id:<thread-id>.
+
+With --all, every notification is marked as read instead, optionally scoped to
+one repository (--repository owner/repo) and to notifications last updated
+before a given age (--older-than, e.g. 12h, 7d or 2w).
+
+Examples:
+  gh-notify read 1             # Mark the first notification from the list as read
+  gh-notify read 1 3 4         # Mark several notifications as read
+  gh-notify read id:9876543210 # Mark a thread as read by its ID
+  gh-notify read --all -r owner/repo --older-than 7d`,
+	Args: func(cmd *cobra.Command, args []string) error {
+		if readAll {
+			return cobra.NoArgs(cmd, args)
+		}
+		return cobra.MinimumNArgs(1)(cmd, args)
+	},
+	RunE: runRead,
+}
+
+var (
+	readAll   bool
+	olderThan string
+)
+
+func init() {
+	readCmd.Flags().StringVarP(&repository, "repository", "r", "", "filter by repository name (same as list; owner/repo with --all)")
+	readCmd.Flags().StringVar(&reason, "reason", "", "filter by notification reason (same as list)")
+	readCmd.Flags().BoolVar(&readAll, "all", false, "mark all notifications as read")
+	readCmd.Flags().StringVar(&olderThan, "older-than", "", "with --all, only notifications last updated before this age (e.g. 12h, 7d, 2w)")
+	readCmd.Flags().BoolVarP(&force, "force", "f", false, "skip confirmation prompt for --all")
+}
+
+func runRead(cmd *cobra.Command, args []string) error {
+	if readAll {
+		return runReadAll(cmd)
+	}
+	if olderThan != "" {
+		return fmt.Errorf("--older-than can only be used with --all")
+	}
+
+	accts, err := accounts()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockAccounts(accts)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	caches, err := loadAccountCaches(accts)
+	if err != nil {
+		return err
+	}
+
+	applyListConfig(cmd.Flags())
+	cached := mergedNotifications(caches)
+	threads, err := resolveThreads(filterNotifications(cached), cached, args)
+	if err != nil {
+		return err
+	}
+
+	// Removing read threads reflects the change locally without waiting for the next sync
+	failed, err := applyToThreads(caches, threads, func(client *github.Client, c *cache.Cache, thread cache.CacheEntry) error {
+		if err := client.MarkThreadRead(thread.ID); err != nil {
+			return err
+		}
+		c.RemoveNotifications(thread.ID)
+		fmt.Printf("✓ Marked as read: %s\n", threadLabel(thread))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("failed to mark %d of %d notifications as read", failed, len(threads))
+	}
+
+	return nil
+}
+
+// runReadAll marks every notification (in a repository) as read on GitHub
+func runReadAll(cmd *cobra.Command) error {
+	// Only flags scope a bulk action: list defaults from the config are not
+	// applied, and GitHub has no bulk endpoint per reason
+	if reason != "" {
+		return fmt.Errorf("--reason cannot be used with --all")
+	}
+	if repository != "" && strings.Count(repository, "/") != 1 {
+		return fmt.Errorf("--repository must be owner/repo with --all, got %q", repository)
+	}
+
+	lastReadAt := time.Now()
+	scope := "all notifications"
+	if olderThan != "" {
+		age, err := parseAge(olderThan)
+		if err != nil {
+			return err
+		}
+		lastReadAt = lastReadAt.Add(-age)
+		scope += fmt.Sprintf(" older than %s", olderThan)
+	}
+	if repository != "" {
+		scope += fmt.Sprintf(" in %s", repository)
+	}
+
+	accts, err := accounts()
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockAccounts(accts)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	caches, err := loadAccountCaches(accts)
+	if err != nil {
+		return err
+	}
+
+	if len(caches) > 1 {
+		scope += fmt.Sprintf(" across %d accounts", len(caches))
+	}
+
+	// Show confirmation unless forced
+	if !force {
+		fmt.Printf("This will mark %s as read on GitHub.\n", scope)
+		confirmed, err := confirm()
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Cancelled.")
+			return nil
+		}
+	}
+
+	removed := 0
+	for _, ac := range caches {
+		ghClient, err := ac.newClient()
+		if err != nil {
+			return err
+		}
+
+		if err := ghClient.MarkAllRead(repository, lastReadAt); err != nil {
+			return err
+		}
+
+		// Reflect the change locally without waiting for the next sync
+		removed += ac.cache.RemoveReadBefore(repository, lastReadAt)
+		if err := ac.save(); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("✓ Marked %s as read (%d cached notifications removed)\n", scope, removed)
+
+	return nil
+}
+
+// parseAge parses a duration that may also use day (d) and week (w) units,
+// e.g. 7d or 2w, in addition to Go durations like 36h
+func parseAge(value string) (time.Duration, error) {
+	units := map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour}
+	for suffix, unit := range units {
+		if num, ok := strings.CutSuffix(value, suffix); ok {
+			n, err := strconv.Atoi(num)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid age %q", value)
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+
+	age, err := time.ParseDuration(value)
+	if err != nil || age < 0 {
+		return 0, fmt.Errorf("invalid age %q", value)
+	}
+
+	return age, nil
+}
+
+// threadIDPrefix marks an argument as a GitHub thread ID rather than a
+// notification number
+const threadIDPrefix = "id:"
+
+// resolveThreads maps arguments to notification threads. A number is a
+// position in the listed (filtered and sorted) notifications and must be in
+// range. A thread ID, cached or not, is given as id:<thread-id>.
+func resolveThreads(listed, cached []cache.CacheEntry, args []string) ([]cache.CacheEntry, error) {
+	byID := make(map[string]cache.CacheEntry, len(cached))
+	for _, entry := range cached {
+		byID[entry.ID] = entry
+	}
+
+	var threads []cache.CacheEntry
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		var thread cache.CacheEntry
+		if id, ok := strings.CutPrefix(arg, threadIDPrefix); ok {
+			if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+				return nil, fmt.Errorf("invalid thread ID: %s", id)
+			}
+			thread, ok = byID[id]
+			if !ok {
+				thread = cache.CacheEntry{ID: id}
+			}
+		} else {
+			num, err := strconv.Atoi(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid notification number: %s (use %s<thread-id> for a thread ID)", arg, threadIDPrefix)
+			}
+			if num < 1 || num > len(listed) {
+				return nil, fmt.Errorf("notification number %d out of range: %d notifications listed", num, len(listed))
+			}
+			thread = listed[num-1]
+		}
+
+		if !seen[thread.ID] {
+			seen[thread.ID] = true
+			threads = append(threads, thread)
+		}
+	}
+
+	return threads, nil
+}
+
+// threadLabel describes a thread for user-facing output
+func threadLabel(thread cache.CacheEntry) string {
+	if thread.Title == "" {
+		return fmt.Sprintf("thread %s", thread.ID)
+	}
+	if thread.Account != "" {
+		return fmt.Sprintf("%s (%s, %s)", thread.Title, thread.Repository, thread.Account)
+	}
+	return fmt.Sprintf("%s (%s)", thread.Title, thread.Repository)
+}
@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+)
+
+// TestResolveThreads tests mapping list numbers and thread IDs to threads
+func TestResolveThreads(t *testing.T) {
+	cached := []cache.CacheEntry{
+		{ID: "9001", Title: "First"},
+		{ID: "9002", Title: "Second"},
+		{ID: "9003", Title: "Third"},
+	}
+	// Listed order differs from cache order, as after sorting by UpdatedAt
+	listed := []cache.CacheEntry{cached[2], cached[0], cached[1]}
+
+	testCases := []struct {
+		name     string
+		args     []string
+		expected []string
+		wantErr  bool
+	}{
+		{name: "list number", args: []string{"1"}, expected: []string{"9003"}},
+		{name: "several numbers", args: []string{"2", "3"}, expected: []string{"9001", "9002"}},
+		{name: "cached thread ID", args: []string{"id:9002"}, expected: []string{"9002"}},
+		{name: "uncached thread ID", args: []string{"id:123456"}, expected: []string{"123456"}},
+		{name: "duplicates collapse", args: []string{"1", "id:9003"}, expected: []string{"9003"}},
+		{name: "zero is invalid", args: []string{"0"}, wantErr: true},
+		{name: "number out of range", args: []string{"4"}, wantErr: true},
+		{name: "bare thread ID is a number", args: []string{"9002"}, wantErr: true},
+		{name: "text is invalid", args: []string{"abc"}, wantErr: true},
+		{name: "text thread ID is invalid", args: []string{"id:abc"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			threads, err := resolveThreads(listed, cached, tc.args)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatal("Expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			if len(threads) != len(tc.expected) {
+				t.Fatalf("Expected %d threads, got %d", len(tc.expected), len(threads))
+			}
+			for i, id := range tc.expected {
+				if threads[i].ID != id {
+					t.Errorf("Expected thread %d to be %s, got %s", i, id, threads[i].ID)
+				}
+			}
+		})
+	}
+
+	t.Logf("✓ Resolve threads test passed!")
+}
+
+// TestFilterNotifications_StableNumbers tests that threads updated at the same
+// time get the same numbers whatever order they were loaded in
+func TestFilterNotifications_StableNumbers(t *testing.T) {
+	now := time.Now().UTC()
+	loaded := []cache.CacheEntry{
+		{ID: "3", UpdatedAt: now},
+		{ID: "1", UpdatedAt: now},
+		{ID: "2", UpdatedAt: now.Add(time.Minute)},
+	}
+	reversed := []cache.CacheEntry{loaded[2], loaded[1], loaded[0]}
+
+	for _, entries := range [][]cache.CacheEntry{loaded, reversed} {
+		var ids []string
+		for _, entry := range filterNotifications(entries) {
+			ids = append(ids, entry.ID)
+		}
+		if got := strings.Join(ids, ","); got != "2,1,3" {
+			t.Errorf("Expected order 2,1,3, got %s", got)
+		}
+	}
+
+	t.Logf("✓ Stable numbering test passed!")
+}
+
+// TestParseAge tests durations with day and week units
+func TestParseAge(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{value: "7d", expected: 7 * 24 * time.Hour},
+		{value: "2w", expected: 14 * 24 * time.Hour},
+		{value: "36h", expected: 36 * time.Hour},
+		{value: "90m", expected: 90 * time.Minute},
+		{value: "0d", expected: 0},
+		{value: "d", wantErr: true},
+		{value: "-1d", wantErr: true},
+		{value: "-1h", wantErr: true},
+		{value: "soon", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.value, func(t *testing.T) {
+			age, err := parseAge(tc.value)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("Expected error for %q, got nil", tc.value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if age != tc.expected {
+				t.Errorf("parseAge(%q) = %v, want %v", tc.value, age, tc.expected)
+			}
+		})
+	}
+
+	t.Logf("✓ Age parsing test passed!")
+}
@@ -5,13 +5,32 @@ import (
 	"os"
 
 	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/config"
+	"github.com/bnema/gh-notify/internal/notifytemplate"
+	"github.com/bnema/gh-notify/internal/quiethours"
+	"github.com/bnema/gh-notify/internal/rules"
+	"github.com/bnema/gh-notify/internal/sink"
 	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
 )
 
 var (
-	cacheDir string
-	verbose  bool
-	cfgFile  string
+	cacheDir    string
+	verbose     bool
+	cfgFile     string
+	hostname    string
+	accountName string
+
+	// cfg holds the resolved config file and environment settings
+	cfg *config.Config
+	// ruleEngine applies the config file rules to notifications
+	ruleEngine *rules.Engine
+	// quietSchedule suppresses desktop alerts during the config quiet hours
+	quietSchedule *quiethours.Schedule
+	// eventSinks forwards new notifications and stars to the config sinks
+	eventSinks *sink.Set
+	// notificationTemplates holds the config templates for desktop notification text
+	notificationTemplates *notifytemplate.Templates
 
 	// Version information (injected at build time via ldflags)
 	version   = "dev"
@@ -48,18 +67,47 @@ func init() {
 	// Global flags
 	rootCmd.PersistentFlags().StringVar(&cacheDir, "cache-dir", "", "cache directory (default: ~/.cache/gh-notify)")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "verbose output")
-	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.gh-notify.yaml)")
+	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default: ~/.config/gh-notify/config.yaml)")
+	rootCmd.PersistentFlags().StringVar(&hostname, "hostname", "", "GitHub hostname, e.g. a GitHub Enterprise Server (default: gh's default host)")
+	rootCmd.PersistentFlags().StringVar(&accountName, "account", "", "only use the named account from the config file")
 
 	// Add subcommands
 	rootCmd.AddCommand(syncCmd)
+	rootCmd.AddCommand(watchCmd)
 	rootCmd.AddCommand(listCmd)
+	rootCmd.AddCommand(historyCmd)
 	rootCmd.AddCommand(openCmd)
+	rootCmd.AddCommand(readCmd)
+	rootCmd.AddCommand(muteCmd)
+	rootCmd.AddCommand(unmuteCmd)
 	rootCmd.AddCommand(clearCmd)
 	rootCmd.AddCommand(installServiceCmd)
 	rootCmd.AddCommand(statusCmd)
+	rootCmd.AddCommand(notifyHelperCmd)
 }
 
 func initConfig() {
+	if cfgFile == "" {
+		cfgFile = os.Getenv(config.EnvPrefix + "CONFIG")
+	}
+
+	var err error
+	cfg, err = config.Load(cfgFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading config: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Load compiled these while validating the config
+	compiled := cfg.Compiled()
+	ruleEngine = compiled.Rules
+	quietSchedule = compiled.QuietHours
+	eventSinks = compiled.Sinks
+	notificationTemplates = compiled.Templates
+
+	configDefault(rootCmd.PersistentFlags(), "cache-dir", &cacheDir, cfg.CacheDir)
+	configDefault(rootCmd.PersistentFlags(), "hostname", &hostname, cfg.Hostname)
+
 	if cacheDir == "" {
 		defaultCacheDir, err := cache.GetDefaultCacheDir()
 		if err != nil {
@@ -69,3 +117,12 @@ func initConfig() {
 		cacheDir = defaultCacheDir
 	}
 }
+
+// configDefault assigns value to target unless the named flag was set on the
+// command line, so that flags always take precedence over the config file.
+func configDefault[T any](flags *pflag.FlagSet, name string, target *T, value T) {
+	if flags.Changed(name) {
+		return
+	}
+	*target = value
+}
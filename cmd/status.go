@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"strings"
 
-	"github.com/bnema/gh-notify/internal/cache"
 	"github.com/bnema/gh-notify/internal/service"
 	"github.com/spf13/cobra"
 )
@@ -52,20 +51,33 @@ func runStatus(cmd *cobra.Command, args []string) error {
 	fmt.Println("\n=== Cache Status ===")
 
 	// Check cache status
-	cache := cache.New(cacheDir)
-	if err := cache.Load(cacheDir); err != nil {
-		fmt.Printf("⚠️  Failed to load cache: %v\n", err)
+	accts, err := accounts()
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
+		return nil
+	}
+
+	caches, err := loadAccountCaches(accts)
+	if err != nil {
+		fmt.Printf("⚠️  %v\n", err)
 		return nil
 	}
 
-	notifications := cache.GetNotifications()
+	notifications := mergedNotifications(caches)
 
 	fmt.Printf("Cached notifications: %d\n", len(notifications))
 
-	if !cache.LastSync.IsZero() {
-		fmt.Printf("Last sync: %s\n", cache.LastSync.Format("2006-01-02 15:04:05"))
-	} else {
-		fmt.Println("Last sync: Never")
+	for _, ac := range caches {
+		prefix := ""
+		if ac.name != "" {
+			prefix = ac.name + " - "
+		}
+
+		if !ac.cache.LastSync.IsZero() {
+			fmt.Printf("%sLast sync: %s\n", prefix, ac.cache.LastSync.Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Printf("%sLast sync: Never\n", prefix)
+		}
 	}
 
 	if len(notifications) > 0 {
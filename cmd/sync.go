@@ -4,11 +4,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
 	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/catalog"
+	"github.com/bnema/gh-notify/internal/config"
+	"github.com/bnema/gh-notify/internal/format"
 	"github.com/bnema/gh-notify/internal/github"
 	"github.com/bnema/gh-notify/internal/logger"
 	"github.com/bnema/gh-notify/internal/nerdfonts"
@@ -18,12 +22,11 @@ import (
 
 const (
 	// Star fetching rate limit - only fetch stars once per hour to avoid GraphQL API limits
-	starFetchRateLimit = 1 * time.Hour
+	// (overridable with sync.star_fetch_interval)
+	starFetchRateLimit = config.DefaultStarFetchInterval
 	// Initial star sync cutoff - on first sync, only fetch stars from last 4 hours
 	// to avoid overwhelming users with historical data
 	initialStarSyncCutoff = 4 * time.Hour
-	// Waybar tooltip star window - show stars from last hour in waybar tooltip
-	waybarStarWindow = 1 * time.Hour
 )
 
 var (
@@ -70,26 +73,158 @@ func runSync(cmd *cobra.Command, args []string) error {
 	// Initialize logger
 	logger.Init(verbose)
 
+	// Flags take precedence over the config file
+	configDefault(cmd.Flags(), "since", &since, cfg.Sync.Since)
+	configDefault(cmd.Flags(), "exclude-stars", &excludeStars, cfg.Sync.ExcludeStars)
+	configDefault(cmd.Flags(), "no-notify", &noNotify, !cfg.Notifier.Enabled)
+
 	logger.Info().Str("cache_dir", cacheDir).Msg("Starting sync")
 
+	accts, err := accounts()
+	if err != nil {
+		return err
+	}
+
+	var newNotifications []cache.CacheEntry
+	var recentStarEvents []cache.StarEvent
+	var failedAccounts []string
+	for _, acct := range accts {
+		notifications, stars, err := syncAccount(acct)
+		if err != nil {
+			// A single account fails the sync as before; with several, the
+			// others still sync and the failure is reported at the end
+			if len(accts) == 1 {
+				return err
+			}
+			logger.Error().Err(err).Str("account", acct.name).Msg("Account sync failed")
+			failedAccounts = append(failedAccounts, acct.label())
+			continue
+		}
+
+		newNotifications = append(newNotifications, notifications...)
+		recentStarEvents = append(recentStarEvents, stars...)
+	}
+
+	// Handle waybar output
+	if waybarOutput {
+		cached, err := queryNotifications(accts, cache.Query{})
+		if err != nil {
+			return err
+		}
+
+		// Apply the current rules so the count matches list
+		notifications := ruleEngine.Apply(cached)
+		totalNotifications := len(notifications)
+
+		// Get stars from cache for tooltip (respects rate limiting)
+		// Only stars from the waybar window are queried
+		recentTooltipStars, err := queryStars(accts, time.Now().UTC().Add(-cfg.Waybar.StarWindow))
+		if err != nil {
+			return err
+		}
+
+		var waybar WaybarOutput
+		totalCount := totalNotifications + len(recentTooltipStars)
+		if totalCount > 0 {
+			var text string
+			if totalNotifications > 0 && len(recentTooltipStars) > 0 {
+				text = fmt.Sprintf("%s (%d) %s (%d)", nerdfonts.GitHub, totalNotifications, nerdfonts.StarredRepo, len(recentTooltipStars))
+			} else if totalNotifications > 0 {
+				text = fmt.Sprintf("%s (%d)", nerdfonts.GitHub, totalNotifications)
+			} else {
+				text = fmt.Sprintf("%s (%d)", nerdfonts.StarredRepo, len(recentTooltipStars))
+			}
+
+			waybar = WaybarOutput{
+				Text:    text,
+				Tooltip: buildTooltip(notifications, recentTooltipStars, cfg.Waybar),
+			}
+		} else {
+			waybar = WaybarOutput{
+				Text:    fmt.Sprintf("%s (0)", nerdfonts.GitHub),
+				Tooltip: "No notifications or recent stars",
+			}
+		}
+
+		jsonOutput, err := json.Marshal(waybar)
+		if err != nil {
+			return fmt.Errorf("failed to marshal waybar output: %w", err)
+		}
+
+		fmt.Println(string(jsonOutput))
+		return nil // Exit early to only output JSON; account failures were logged
+	}
+
+	// Output summary
+	var summaryParts []string
+	if len(newNotifications) > 0 {
+		summaryParts = append(summaryParts, fmt.Sprintf("%d new notifications", len(newNotifications)))
+	}
+	if len(recentStarEvents) > 0 {
+		summaryParts = append(summaryParts, fmt.Sprintf("%d new stars", len(recentStarEvents)))
+	}
+
+	if len(summaryParts) > 0 {
+		fmt.Printf("✓ %s found\n", strings.Join(summaryParts, " and "))
+		if !noNotify {
+			fmt.Println("✓ Desktop notifications sent")
+		}
+	} else {
+		fmt.Println("✓ No new notifications or stars")
+	}
+
+	if len(failedAccounts) > 0 {
+		return fmt.Errorf("failed to sync %d of %d accounts: %s", len(failedAccounts), len(accts), strings.Join(failedAccounts, ", "))
+	}
+
+	return nil
+}
+
+// syncAccount syncs one account: it updates the account's cache, then hands
+// the new notifications and star events to the sinks and returns them
+func syncAccount(acct account) ([]cache.CacheEntry, []cache.StarEvent, error) {
+	newNotifications, recentStarEvents, err := updateAccountCache(acct)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Sinks get every new event, even with --no-notify or during quiet hours.
+	// The cache lock is released by now, as exec timeouts and webhook retries
+	// can take a while.
+	eventSinks.Deliver(acct.name, newNotifications, recentStarEvents)
+
+	return newNotifications, recentStarEvents, nil
+}
+
+// updateAccountCache fetches notifications and stars into the account's
+// cache, sends desktop alerts and saves the cache, holding the cache lock
+// throughout. It returns the new notifications and star events.
+func updateAccountCache(acct account) ([]cache.CacheEntry, []cache.StarEvent, error) {
+	// Hold the cache lock until the cache is saved
+	lock, err := acct.lockCache()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = lock.Unlock() }()
+
 	// Initialize cache
-	c := cache.New(cacheDir)
-	if err := c.Load(cacheDir); err != nil {
-		return fmt.Errorf("failed to load cache: %w", err)
+	c, err := acct.loadCache()
+	if err != nil {
+		return nil, nil, err
 	}
 
 	logger.Debug().Int("cached_notifications", len(c.GetNotifications())).Msg("Cache loaded")
 
 	// Initialize GitHub client
 	startAuth := time.Now()
-	ghClient, err := github.NewClient()
+	ghClient, err := acct.newClient()
 	if err != nil {
-		return fmt.Errorf("failed to create GitHub client: %w", err)
+		return nil, nil, err
 	}
 
 	// Test authentication
 	if err := ghClient.TestAuth(); err != nil {
-		return fmt.Errorf("GitHub authentication failed: %w", err)
+		return nil, nil, fmt.Errorf("GitHub authentication failed for %s: %w", acct.label(), err)
 	}
 
 	logger.Debug().Dur("duration", time.Since(startAuth)).Msg("GitHub authentication successful")
@@ -98,213 +233,352 @@ func runSync(cmd *cobra.Command, args []string) error {
 
 	// Fetch notifications (unless stars-only mode)
 	if !starsOnly {
-		startNotif := time.Now()
-		notifications, err := ghClient.FetchNotifications()
+		newNotifications, _, err = syncNotifications(ghClient, c)
 		if err != nil {
-			return fmt.Errorf("failed to fetch notifications: %w", err)
-		}
-
-		logger.Debug().
-			Int("count", len(notifications)).
-			Dur("duration", time.Since(startNotif)).
-			Msg("Fetched notifications from GitHub")
-
-		// Filter by time if since is specified
-		if since > 0 {
-			cutoff := time.Now().UTC().Add(-since)
-			var filtered []cache.CacheEntry
-			for _, notif := range notifications {
-				if notif.UpdatedAt.After(cutoff) {
-					filtered = append(filtered, notif)
-				}
-			}
-			notifications = filtered
-
-			logger.Debug().
-				Int("filtered_count", len(filtered)).
-				Dur("since", since).
-				Msg("Filtered notifications by time")
+			return nil, nil, err
 		}
-
-		// Add notifications to cache and get new ones
-		newNotifications = c.AddNotifications(notifications)
-
-		logger.Info().
-			Int("new_count", len(newNotifications)).
-			Msg("New notifications found")
 	}
 
 	// Fetch star events if not excluded (stars are tracked by default)
 	var recentStarEvents []cache.StarEvent
 	if !excludeStars || starsOnly {
-		// Rate limit: only fetch stars if at least starFetchRateLimit has passed since last fetch
+		// Rate limit: only fetch stars if at least the star fetch interval has passed since last fetch
+		fetchInterval := cfg.Sync.StarFetchInterval
 		timeSinceLastFetch := time.Since(c.LastEventSync)
-		if !c.LastEventSync.IsZero() && timeSinceLastFetch < starFetchRateLimit {
+		if !c.LastEventSync.IsZero() && timeSinceLastFetch < fetchInterval {
 			logger.Info().
 				Dur("time_since_last_fetch", timeSinceLastFetch).
-				Dur("time_until_next_fetch", starFetchRateLimit-timeSinceLastFetch).
-				Msg("Skipping star fetch - rate limit (fetches limited to once per hour)")
+				Dur("time_until_next_fetch", fetchInterval-timeSinceLastFetch).
+				Msg("Skipping star fetch - rate limit")
 		} else {
-			// Get cutoff time - only check for stars since last sync
-			cutoff := c.LastEventSync
-			if cutoff.IsZero() {
-				// If no previous sync, only show stars from initialStarSyncCutoff to avoid overwhelming users with historical star data on initial sync
-				cutoff = time.Now().UTC().Add(-initialStarSyncCutoff)
-				logger.Info().Time("cutoff", cutoff).Msg("First star sync - using initial cutoff")
-			} else {
-				logger.Debug().Time("cutoff", cutoff).Msg("Using last sync time as cutoff")
-			}
-
-			startStars := time.Now()
-			logger.Info().Msg("Fetching star events using GraphQL...")
-			starEvents, err := ghClient.FetchRecentStars(cutoff)
+			recentStarEvents, err = syncStars(ghClient, c)
 			if err != nil {
-				return fmt.Errorf("failed to fetch star events: %w", err)
+				return nil, nil, err
 			}
-
-			// Add to cache and get only new stars
-			recentStarEvents = c.AddStarEvents(starEvents)
-
-			logger.Info().
-				Int("new_stars", len(recentStarEvents)).
-				Dur("total_duration", time.Since(startStars)).
-				Msg("Star sync completed")
-
-			// Update last event sync time after successful fetch (use UTC to match GitHub API)
-			c.LastEventSync = time.Now().UTC()
 		}
 	}
 
 	// Send desktop notifications for new notifications and star events
 	if !noNotify {
-		notifier := notifier.New(true)
-
-		// Send notifications for regular GitHub notifications
-		if len(newNotifications) > 0 {
-			if err := notifier.SendBulkNotification(newNotifications); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to send desktop notification: %v\n", err)
-			} else if verbose {
-				fmt.Println("Desktop notification sent for regular notifications")
-			}
-		}
-
-		// Send notifications for new star events
-		if len(recentStarEvents) > 0 {
-			if err := notifier.SendStarNotifications(recentStarEvents); err != nil {
-				fmt.Fprintf(os.Stderr, "Warning: failed to send star notification: %v\n", err)
-			} else if verbose {
-				fmt.Println("Desktop notification sent for new stars")
-			}
+		// A detached helper waits for buttons so sync returns right away
+		n, err := newNotifier(acct, ghClient, &helperDispatcher{acct: acct})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping desktop notifications: %v\n", err)
+		} else {
+			deliverAlerts(n, c, newNotifications, recentStarEvents, time.Now())
 		}
 	}
 
 	// Save updated cache (includes LastEventSync if stars were fetched)
-	if err := c.Save(cacheDir); err != nil {
-		return fmt.Errorf("failed to save cache: %w", err)
+	if err := acct.saveCache(c); err != nil {
+		return nil, nil, err
 	}
 
 	if verbose {
 		fmt.Println("Cache saved successfully")
 	}
 
-	// Handle waybar output
-	if waybarOutput {
-		totalNotifications := len(c.GetNotifications())
+	return newNotifications, recentStarEvents, nil
+}
 
-		// Get stars from cache for tooltip (respects rate limiting)
-		// Filter cached stars to only show those from the waybar window
-		var recentTooltipStars []cache.StarEvent
-		starWindowCutoff := time.Now().UTC().Add(-waybarStarWindow)
-		allStars := c.GetStars()
-		for _, star := range allStars {
-			if star.StarredAt.After(starWindowCutoff) {
-				recentTooltipStars = append(recentTooltipStars, star)
+// syncNotifications fetches unread notifications, applies the --since filter and
+// replaces the cached notifications with them. It returns the notifications that
+// are new since the previous sync, minus muted and silenced threads, and the
+// poll interval requested by GitHub. Config rules are applied before caching.
+// Polls are conditional on the cached Last-Modified value, so an unchanged
+// notification list leaves the cache untouched.
+func syncNotifications(ghClient github.GitHubClientInterface, c *cache.Cache) ([]cache.CacheEntry, time.Duration, error) {
+	startNotif := time.Now()
+	result, err := ghClient.FetchNotifications(c.LastModified)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to fetch notifications: %w", err)
+	}
+
+	// 304 Not Modified: the cached notifications are still current
+	if result.NotModified {
+		c.LastSync = time.Now().UTC()
+
+		logger.Debug().
+			Dur("poll_interval", result.PollInterval).
+			Dur("duration", time.Since(startNotif)).
+			Msg("Notifications not modified since last sync")
+
+		return nil, result.PollInterval, nil
+	}
+
+	notifications := result.Entries
+
+	logger.Debug().
+		Int("count", len(notifications)).
+		Bool("truncated", result.Truncated).
+		Dur("poll_interval", result.PollInterval).
+		Dur("duration", time.Since(startNotif)).
+		Msg("Fetched notifications from GitHub")
+
+	// Filter by time if since is specified
+	if since > 0 {
+		cutoff := time.Now().UTC().Add(-since)
+		var filtered []cache.CacheEntry
+		for _, notif := range notifications {
+			if notif.UpdatedAt.After(cutoff) {
+				filtered = append(filtered, notif)
 			}
 		}
+		notifications = filtered
 
-		var waybar WaybarOutput
-		totalCount := totalNotifications + len(recentTooltipStars)
-		if totalCount > 0 {
-			var text string
-			if totalNotifications > 0 && len(recentTooltipStars) > 0 {
-				text = fmt.Sprintf("%s (%d) %s (%d)", nerdfonts.GitHub, totalNotifications, nerdfonts.StarredRepo, len(recentTooltipStars))
-			} else if totalNotifications > 0 {
-				text = fmt.Sprintf("%s (%d)", nerdfonts.GitHub, totalNotifications)
-			} else {
-				text = fmt.Sprintf("%s (%d)", nerdfonts.StarredRepo, len(recentTooltipStars))
-			}
+		logger.Debug().
+			Int("filtered_count", len(filtered)).
+			Dur("since", since).
+			Msg("Filtered notifications by time")
+	}
 
-			waybar = WaybarOutput{
-				Text:    text,
-				Tooltip: buildTooltip(c.GetNotifications(), recentTooltipStars),
-			}
+	// Dropped notifications are never cached; the rest carry rule annotations
+	fetched := len(notifications)
+	notifications = ruleEngine.Apply(notifications)
+	if dropped := fetched - len(notifications); dropped > 0 {
+		logger.Debug().Int("dropped", dropped).Msg("Notifications dropped by rules")
+	}
+
+	// Deep-link to the exact comment or release instead of guessed URLs
+	notifications = ghClient.ResolveWebURLs(notifications, c.GetNotifications())
+
+	// Tell merged or closed subjects apart from live ones
+	notifications = ghClient.EnrichSubjects(notifications, c.GetNotifications())
+
+	// Add notifications to cache and get new ones. A truncated list is merged
+	// so threads beyond the page limit are not dropped as if they were read.
+	// A list filtered by --since or rules is not the whole unread set, so the
+	// history must not mark the missing threads as gone.
+	complete := !result.Truncated && len(notifications) == len(result.Entries)
+	var newNotifications []cache.CacheEntry
+	if result.Truncated {
+		newNotifications = c.MergeNotifications(notifications)
+	} else {
+		newNotifications = c.ReplaceNotifications(notifications, complete)
+	}
+
+	// A 304 keeps the cached set, so it must only answer for a complete one.
+	// Otherwise the next sync fetches in full, and a removed rule or a sync
+	// without --since takes effect.
+	c.LastModified = ""
+	if complete {
+		c.LastModified = result.LastModified
+	}
+
+	// Muted and silenced threads stay cached (and listed) but never raise alerts
+	alertable := newNotifications[:0]
+	for _, notif := range newNotifications {
+		if c.IsMuted(notif.ID) || notif.Silent {
+			logger.Debug().Str("thread_id", notif.ID).Msg("Skipping muted or silenced thread")
+			continue
+		}
+		alertable = append(alertable, notif)
+	}
+	newNotifications = alertable
+
+	logger.Info().
+		Int("new_count", len(newNotifications)).
+		Msg("New notifications found")
+
+	return newNotifications, result.PollInterval, nil
+}
+
+// syncStars fetches star events since the last star sync, adds them to the cache
+// and returns only the new ones. Callers decide when a fetch is due.
+func syncStars(ghClient github.GitHubClientInterface, c *cache.Cache) ([]cache.StarEvent, error) {
+	// Get cutoff time - only check for stars since last sync
+	cutoff := c.LastEventSync
+	if cutoff.IsZero() {
+		// If no previous sync, only show stars from initialStarSyncCutoff to avoid overwhelming users with historical star data on initial sync
+		cutoff = time.Now().UTC().Add(-initialStarSyncCutoff)
+		logger.Info().Time("cutoff", cutoff).Msg("First star sync - using initial cutoff")
+	} else {
+		logger.Debug().Time("cutoff", cutoff).Msg("Using last sync time as cutoff")
+	}
+
+	startStars := time.Now()
+	logger.Info().Msg("Fetching star events using GraphQL...")
+	starEvents, err := ghClient.FetchRecentStars(cutoff)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch star events: %w", err)
+	}
+
+	// Add to cache and get only new stars
+	newStarEvents := c.AddStarEvents(starEvents)
+
+	logger.Info().
+		Int("new_stars", len(newStarEvents)).
+		Dur("total_duration", time.Since(startStars)).
+		Msg("Star sync completed")
+
+	// Update last event sync time after successful fetch (use UTC to match GitHub API)
+	c.LastEventSync = time.Now().UTC()
+
+	return newStarEvents, nil
+}
+
+// deliverAlerts sends desktop alerts while honouring quiet hours. Inside a
+// quiet window alerts are held in the cache, except for allowed reasons. The
+// first delivery after the window ends sends the held alerts, together with
+// any new ones, as a single catch-up summary.
+func deliverAlerts(n *notifier.Notifier, c *cache.Cache, notifications []cache.CacheEntry, stars []cache.StarEvent, now time.Time) {
+	quiet := quietSchedule.Active(now)
+
+	// The persistent summary stands in for notification alerts; stars and, in
+	// quiet hours, allowed reasons still alert
+	if cfg.Notifier.Summary {
+		var allowed []cache.CacheEntry
+		if quiet {
+			allowed, notifications = splitAllowed(notifications)
+		}
+		updateSummary(n, c, notifications, quiet)
+		notifications = allowed
+	}
+
+	if quiet {
+		allowed, held := splitAllowed(notifications)
+
+		if len(held) > 0 || len(stars) > 0 {
+			c.HoldAlerts(held, stars)
+			logger.Info().
+				Int("notifications", len(held)).
+				Int("stars", len(stars)).
+				Msg("Quiet hours - holding alerts")
+		}
+
+		sendDesktopNotifications(n, allowed, nil)
+		return
+	}
+
+	if c.HasHeldAlerts() {
+		heldNotifications, heldStars := c.TakeHeldAlerts()
+		heldNotifications = append(heldNotifications, notifications...)
+		heldStars = append(heldStars, stars...)
+
+		if err := n.SendCatchUpNotification(heldNotifications, heldStars); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to send catch-up notification: %v\n", err)
+		} else if verbose {
+			fmt.Println("Catch-up notification sent for quiet hours")
+		}
+		return
+	}
+
+	sendDesktopNotifications(n, notifications, stars)
+}
+
+// splitAllowed separates the notifications whose reason may alert during quiet
+// hours from those that are held
+func splitAllowed(notifications []cache.CacheEntry) (allowed, held []cache.CacheEntry) {
+	for _, notif := range notifications {
+		if slices.Contains(cfg.QuietHours.AllowReasons, notif.Reason) {
+			allowed = append(allowed, notif)
 		} else {
-			waybar = WaybarOutput{
-				Text:    fmt.Sprintf("%s (0)", nerdfonts.GitHub),
-				Tooltip: "No notifications or recent stars",
-			}
+			held = append(held, notif)
 		}
+	}
+	return allowed, held
+}
 
-		jsonOutput, err := json.Marshal(waybar)
-		if err != nil {
-			return fmt.Errorf("failed to marshal waybar output: %w", err)
+// updateSummary replaces the persistent unread summary with the current count,
+// or closes it once nothing is unread. It is left alone while the count is
+// unchanged and nothing is new, and during quiet hours unless it can close.
+func updateSummary(n *notifier.Notifier, c *cache.Cache, newNotifications []cache.CacheEntry, quiet bool) {
+	unread := c.GetNotifications()
+
+	if len(unread) == 0 {
+		if c.SummaryID != 0 {
+			if err := n.CloseSummary(c.SummaryID); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: failed to close summary notification: %v\n", err)
+			}
+			c.SummaryID, c.SummaryCount = 0, 0
 		}
+		return
+	}
 
-		fmt.Println(string(jsonOutput))
-		return nil // Exit early to only output JSON
+	if quiet || (len(newNotifications) == 0 && len(unread) == c.SummaryCount) {
+		return
 	}
 
-	// Output summary
-	var summaryParts []string
-	if len(newNotifications) > 0 {
-		summaryParts = append(summaryParts, fmt.Sprintf("%d new notifications", len(newNotifications)))
+	id, err := n.SendSummary(unread, newNotifications, c.SummaryID)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to update summary notification: %v\n", err)
+		return
 	}
-	if len(recentStarEvents) > 0 {
-		summaryParts = append(summaryParts, fmt.Sprintf("%d new stars", len(recentStarEvents)))
+	c.SummaryID, c.SummaryCount = id, len(unread)
+
+	if verbose {
+		fmt.Printf("Summary notification updated: %d unread\n", len(unread))
 	}
+}
 
-	if len(summaryParts) > 0 {
-		fmt.Printf("✓ %s found\n", strings.Join(summaryParts, " and "))
-		if !noNotify {
-			fmt.Println("✓ Desktop notifications sent")
+// sendDesktopNotifications alerts on new notifications and star events.
+// Failures are reported as warnings since they should never abort a sync.
+func sendDesktopNotifications(n *notifier.Notifier, notifications []cache.CacheEntry, stars []cache.StarEvent) {
+	// Send notifications for regular GitHub notifications
+	if len(notifications) > 0 {
+		if err := n.SendBulkNotification(notifications); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to send desktop notification: %v\n", err)
+		} else if verbose {
+			fmt.Println("Desktop notification sent for regular notifications")
 		}
-	} else {
-		fmt.Println("✓ No new notifications or stars")
 	}
 
-	return nil
+	// Send notifications for new star events
+	if len(stars) > 0 {
+		if err := n.SendStarNotifications(stars); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to send star notification: %v\n", err)
+		} else if verbose {
+			fmt.Println("Desktop notification sent for new stars")
+		}
+	}
 }
 
-func buildTooltip(notifications []cache.CacheEntry, recentStars []cache.StarEvent) string {
-	const maxLineLen = 80 // Maximum characters per tooltip line
+func buildTooltip(notifications []cache.CacheEntry, recentStars []cache.StarEvent, opts config.WaybarConfig) string {
 	var tooltip strings.Builder
 
 	// Add notifications section
 	if len(notifications) > 0 {
 		tooltip.WriteString("GitHub Notifications:\n")
 
-		// Sort notifications by repository for better organization
+		// Sort notifications by account and repository for better organization,
+		// then most important first within a repository, then newest
 		sort.Slice(notifications, func(i, j int) bool {
-			if notifications[i].Repository != notifications[j].Repository {
-				return notifications[i].Repository < notifications[j].Repository
+			a, b := notifications[i], notifications[j]
+			if a.Account != b.Account {
+				return a.Account < b.Account
 			}
-			return notifications[i].UpdatedAt.After(notifications[j].UpdatedAt)
+			if a.Repository != b.Repository {
+				return a.Repository < b.Repository
+			}
+			if c := catalog.Compare(a.Reason, a.Type, b.Reason, b.Type); c != 0 {
+				return c < 0
+			}
+			return a.UpdatedAt.After(b.UpdatedAt)
 		})
 
 		currentRepo := ""
 		for _, notif := range notifications {
-			if notif.Repository != currentRepo {
+			// Prefix the repository with its account when several are polled
+			repo := notif.Repository
+			if notif.Account != "" {
+				repo = fmt.Sprintf("[%s] %s", notif.Account, notif.Repository)
+			}
+
+			if repo != currentRepo {
 				if currentRepo != "" {
 					tooltip.WriteString("\n")
 				}
-				tooltip.WriteString(fmt.Sprintf("%s %s:\n", nerdfonts.Repository, notif.Repository))
-				currentRepo = notif.Repository
+				tooltip.WriteString(fmt.Sprintf("%s %s:\n", nerdfonts.Repository, repo))
+				currentRepo = repo
 			}
 
 			// Format notification with Nerd Font icon
-			icon := getNotificationIcon(notif.Reason, notif.Type)
-			line := fmt.Sprintf("  %s %s (%s)", icon, notif.Title, notif.Reason)
-			tooltip.WriteString(truncateString(line, maxLineLen) + "\n")
+			icon := catalog.Icon(notif.Reason, notif.Type)
+			line := fmt.Sprintf("  %s %s (%s)", icon, notif.Title, catalog.Reason(notif.Reason).Label)
+			if state := notif.Subject.Label(); state != "" {
+				line += fmt.Sprintf(" [%s]", state)
+			}
+			tooltip.WriteString(format.Truncate(line, opts.MaxLineLength) + "\n")
 		}
 	}
 
@@ -313,7 +587,7 @@ func buildTooltip(notifications []cache.CacheEntry, recentStars []cache.StarEven
 		if len(notifications) > 0 {
 			tooltip.WriteString("\n")
 		}
-		tooltip.WriteString(fmt.Sprintf("%s Recent Stars (last hour):\n", nerdfonts.StarredRepo))
+		tooltip.WriteString(fmt.Sprintf("%s Recent Stars (last %s):\n", nerdfonts.StarredRepo, format.Age(opts.StarWindow)))
 
 		// Sort stars by time (newest first)
 		sort.Slice(recentStars, func(i, j int) bool {
@@ -324,7 +598,7 @@ func buildTooltip(notifications []cache.CacheEntry, recentStars []cache.StarEven
 			timeAgo := time.Since(star.StarredAt).Round(time.Minute)
 			line := fmt.Sprintf("  %s %s starred %s (%v ago)",
 				nerdfonts.StarredRepo, star.StarredBy, star.Repository, timeAgo)
-			tooltip.WriteString(truncateString(line, maxLineLen) + "\n")
+			tooltip.WriteString(format.Truncate(line, opts.MaxLineLength) + "\n")
 		}
 	}
 
@@ -334,29 +608,3 @@ func buildTooltip(notifications []cache.CacheEntry, recentStars []cache.StarEven
 
 	return tooltip.String()
 }
-
-func getNotificationIcon(reason, notifType string) string {
-	switch reason {
-	case "review_requested":
-		return nerdfonts.ReviewRequested
-	case "assign":
-		return nerdfonts.Assign
-	case "mention":
-		return nerdfonts.Mention
-	case "author":
-		return nerdfonts.Author
-	case "state_change":
-		return nerdfonts.StateChange
-	default:
-		switch notifType {
-		case "PullRequest":
-			return nerdfonts.PullRequest
-		case "Issue":
-			return nerdfonts.Issue
-		case "Release":
-			return nerdfonts.Release
-		default:
-			return nerdfonts.DefaultNotif
-		}
-	}
-}
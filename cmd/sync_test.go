@@ -2,10 +2,16 @@ package cmd
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/config"
+	"github.com/bnema/gh-notify/internal/github"
+	"github.com/bnema/gh-notify/internal/notifier"
+	"github.com/bnema/gh-notify/internal/quiethours"
+	"github.com/bnema/gh-notify/internal/rules"
 )
 
 // TestSync_FirstStarSync_4HourCutoff tests that first sync uses 4-hour cutoff
@@ -305,3 +311,337 @@ func TestSync_RateLimitCheck(t *testing.T) {
 
 	t.Logf("✓ Rate limit check test passed!")
 }
+
+// stubClient returns a fixed notifications result; other methods are unused
+type stubClient struct {
+	github.GitHubClientInterface
+	result *github.NotificationsResult
+}
+
+func (s *stubClient) FetchNotifications(lastModified string) (*github.NotificationsResult, error) {
+	return s.result, nil
+}
+
+func (s *stubClient) ResolveWebURLs(entries, cached []cache.CacheEntry) []cache.CacheEntry {
+	return entries
+}
+
+func (s *stubClient) EnrichSubjects(entries, cached []cache.CacheEntry) []cache.CacheEntry {
+	return entries
+}
+
+// TestSync_MutedThreadsSkipAlerts tests that muted threads are cached but not alerted
+func TestSync_MutedThreadsSkipAlerts(t *testing.T) {
+	c := cache.New("")
+	c.Mute("2")
+
+	client := &stubClient{result: &github.NotificationsResult{
+		Entries: []cache.CacheEntry{{ID: "1"}, {ID: "2"}},
+	}}
+
+	newNotifications, _, err := syncNotifications(client, c)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(newNotifications) != 1 || newNotifications[0].ID != "1" {
+		t.Errorf("Expected only thread '1' to be alerted, got %+v", newNotifications)
+	}
+
+	if len(c.GetNotifications()) != 2 {
+		t.Errorf("Expected muted thread to stay cached, got %d notifications", len(c.GetNotifications()))
+	}
+
+	t.Logf("✓ Muted threads test passed!")
+}
+
+// TestSync_RulesDropAndSilence tests that rules apply before caching and alerting
+func TestSync_RulesDropAndSilence(t *testing.T) {
+	engine, err := rules.New([]rules.Rule{
+		{Reason: "ci_activity", Action: rules.ActionDrop},
+		{Repository: "my-org/*", Action: rules.ActionSilent},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	ruleEngine = engine
+	t.Cleanup(func() { ruleEngine = nil })
+
+	c := cache.New("")
+	client := &stubClient{result: &github.NotificationsResult{
+		LastModified: "Mon, 12 Oct 2026 10:00:00 GMT",
+		Entries: []cache.CacheEntry{
+			{ID: "1", Repository: "owner/repo", Reason: "ci_activity"},
+			{ID: "2", Repository: "my-org/tool", Reason: "mention"},
+			{ID: "3", Repository: "owner/repo", Reason: "mention"},
+		},
+	}}
+
+	newNotifications, _, err := syncNotifications(client, c)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(newNotifications) != 1 || newNotifications[0].ID != "3" {
+		t.Errorf("Expected only thread '3' to be alerted, got %+v", newNotifications)
+	}
+
+	cached := c.GetNotifications()
+	if len(cached) != 2 {
+		t.Fatalf("Expected dropped thread not to be cached, got %d notifications", len(cached))
+	}
+	if c.LastModified != "" {
+		t.Errorf("Expected no Last-Modified for a set reduced by rules, got %q", c.LastModified)
+	}
+	if !cached[0].Silent {
+		t.Errorf("Expected silenced thread to be cached as silent, got %+v", cached[0])
+	}
+
+	t.Logf("✓ Sync rules test passed!")
+}
+
+// TestSync_SinceKeepsHistoryOpen tests that a --since filtered fetch does not
+// mark older, still unread threads as gone in the history
+func TestSync_SinceKeepsHistoryOpen(t *testing.T) {
+	now := time.Now().UTC()
+	c := cache.New("")
+	c.AddNotifications([]cache.CacheEntry{{ID: "old", UpdatedAt: now.Add(-48 * time.Hour)}})
+
+	since = time.Hour
+	t.Cleanup(func() { since = 0 })
+
+	client := &stubClient{result: &github.NotificationsResult{
+		LastModified: "Mon, 12 Oct 2026 10:00:00 GMT",
+		Entries: []cache.CacheEntry{
+			{ID: "old", UpdatedAt: now.Add(-48 * time.Hour)},
+			{ID: "new", UpdatedAt: now},
+		},
+	}}
+	if _, _, err := syncNotifications(client, c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if c.LastModified != "" {
+		t.Errorf("Expected no Last-Modified for a --since filtered set, got %q", c.LastModified)
+	}
+
+	for _, record := range c.History() {
+		if record.Status() != "unread" {
+			t.Errorf("Expected thread %s to stay unread in the history, got %s", record.ID, record.Status())
+		}
+	}
+
+	// An unfiltered fetch is the whole unread set again
+	since = 0
+	client.result = &github.NotificationsResult{
+		LastModified: "Mon, 12 Oct 2026 11:00:00 GMT",
+		Entries:      []cache.CacheEntry{{ID: "new", UpdatedAt: now}},
+	}
+	if _, _, err := syncNotifications(client, c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if c.LastModified != "Mon, 12 Oct 2026 11:00:00 GMT" {
+		t.Errorf("Expected Last-Modified to be kept after a full fetch, got %q", c.LastModified)
+	}
+	for _, record := range c.History() {
+		if record.ID == "old" && record.Status() != "gone" {
+			t.Errorf("Expected the old thread to be gone after a full fetch, got %s", record.Status())
+		}
+	}
+
+	t.Logf("✓ Since history test passed!")
+}
+
+// TestDeliverAlerts_QuietHours tests holding alerts in quiet hours and releasing them afterwards
+func TestDeliverAlerts_QuietHours(t *testing.T) {
+	schedule, err := quiethours.New([]quiethours.Window{{Start: "22:00", End: "07:00"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	quietSchedule = schedule
+	cfg = config.Default()
+	cfg.QuietHours.AllowReasons = []string{"security_alert"}
+	t.Cleanup(func() {
+		quietSchedule = nil
+		cfg = nil
+	})
+
+	// Disabled notifier: delivery is a no-op, only the held state is checked
+	n := notifier.New(false)
+	c := cache.New("")
+	notifications := []cache.CacheEntry{
+		{ID: "1", Reason: "mention"},
+		{ID: "2", Reason: "security_alert"},
+	}
+	c.AddNotifications(notifications)
+
+	night := time.Date(2025, 1, 6, 23, 0, 0, 0, time.Local)
+	deliverAlerts(n, c, notifications, nil, night)
+
+	if len(c.HeldNotifications) != 1 || c.HeldNotifications[0] != "1" {
+		t.Errorf("Expected only thread '1' to be held, got %v", c.HeldNotifications)
+	}
+
+	morning := time.Date(2025, 1, 7, 8, 0, 0, 0, time.Local)
+	deliverAlerts(n, c, nil, nil, morning)
+
+	if c.HasHeldAlerts() {
+		t.Errorf("Expected held alerts to be released after quiet hours, got %v", c.HeldNotifications)
+	}
+
+	t.Logf("✓ Quiet hours delivery test passed!")
+}
+
+// summaryBackend records summary notifications, answering with the replaced
+// ID or a new one
+type summaryBackend struct {
+	shown  []notifier.Notification
+	closed []uint32
+}
+
+func (b *summaryBackend) Show(n notifier.Notification) (notifier.Result, error) {
+	b.shown = append(b.shown, n)
+	if n.ReplacesID != 0 {
+		return notifier.Result{ID: n.ReplacesID}, nil
+	}
+	return notifier.Result{ID: 7}, nil
+}
+
+func (b *summaryBackend) CloseNotification(id uint32) error {
+	b.closed = append(b.closed, id)
+	return nil
+}
+
+func (b *summaryBackend) Close() error {
+	return nil
+}
+
+// TestDeliverAlerts_Summary tests that the summary is replaced in place, kept
+// while nothing changes, and closed once nothing is unread
+func TestDeliverAlerts_Summary(t *testing.T) {
+	cfg = config.Default()
+	cfg.Notifier.Summary = true
+	t.Cleanup(func() { cfg = nil })
+
+	backend := &summaryBackend{}
+	n := notifier.New(true)
+	n.SetBackend(backend)
+	c := cache.New("")
+	now := time.Date(2025, 1, 6, 12, 0, 0, 0, time.Local)
+
+	first := []cache.CacheEntry{{ID: "1", Repository: "owner/repo", Reason: "mention"}}
+	deliverAlerts(n, c, c.AddNotifications(first), nil, now)
+
+	if len(backend.shown) != 1 || backend.shown[0].ReplacesID != 0 || backend.shown[0].Title != "GitHub: 1 unread" {
+		t.Fatalf("Expected a new summary, got %+v", backend.shown)
+	}
+	if c.SummaryID != 7 || c.SummaryCount != 1 {
+		t.Errorf("Expected summary 7 with 1 unread in the cache, got %d with %d", c.SummaryID, c.SummaryCount)
+	}
+
+	// Nothing new and the same count: the summary is left alone
+	deliverAlerts(n, c, nil, nil, now)
+	if len(backend.shown) != 1 {
+		t.Errorf("Expected no update for an unchanged count, got %d notifications", len(backend.shown))
+	}
+
+	second := append(first, cache.CacheEntry{ID: "2", Repository: "owner/tool", Reason: "assign"})
+	deliverAlerts(n, c, c.AddNotifications(second), nil, now)
+
+	if len(backend.shown) != 2 || backend.shown[1].ReplacesID != 7 || backend.shown[1].Title != "GitHub: 2 unread" {
+		t.Errorf("Expected the summary to be replaced, got %+v", backend.shown)
+	}
+	if len(backend.shown[1].Actions) != 0 {
+		t.Errorf("Expected a summary without buttons, got %+v", backend.shown[1].Actions)
+	}
+
+	c.AddNotifications(nil)
+	deliverAlerts(n, c, nil, nil, now)
+
+	if len(backend.closed) != 1 || backend.closed[0] != 7 {
+		t.Errorf("Expected summary 7 to be closed, got %v", backend.closed)
+	}
+	if c.SummaryID != 0 || c.SummaryCount != 0 {
+		t.Errorf("Expected the summary to be forgotten, got %d with %d", c.SummaryID, c.SummaryCount)
+	}
+
+	t.Logf("✓ Summary delivery test passed!")
+}
+
+// TestDeliverAlerts_SummaryQuietHours tests that allowed reasons still alert
+// in quiet hours while the summary waits
+func TestDeliverAlerts_SummaryQuietHours(t *testing.T) {
+	schedule, err := quiethours.New([]quiethours.Window{{Start: "22:00", End: "07:00"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	quietSchedule = schedule
+	cfg = config.Default()
+	cfg.Notifier.Summary = true
+	cfg.QuietHours.AllowReasons = []string{"security_alert"}
+	t.Cleanup(func() {
+		quietSchedule = nil
+		cfg = nil
+	})
+
+	backend := &summaryBackend{}
+	n := notifier.New(true)
+	n.SetBackend(backend)
+	c := cache.New("")
+
+	night := time.Date(2025, 1, 6, 23, 0, 0, 0, time.Local)
+	notifications := c.AddNotifications([]cache.CacheEntry{
+		{ID: "1", Repository: "owner/repo", Reason: "mention"},
+		{ID: "2", Repository: "owner/repo", Reason: "security_alert", Title: "Vulnerable dependency"},
+	})
+	deliverAlerts(n, c, notifications, nil, night)
+
+	if len(backend.shown) != 1 || !strings.Contains(backend.shown[0].Body+backend.shown[0].Title, "Vulnerable dependency") {
+		t.Errorf("Expected only the security alert to be shown, got %+v", backend.shown)
+	}
+	if c.SummaryID != 0 {
+		t.Errorf("Expected the summary to wait for quiet hours to end, got %d", c.SummaryID)
+	}
+
+	t.Logf("✓ Summary quiet hours test passed!")
+}
+
+// TestBuildTooltip_AccountPrefix tests that repositories are prefixed with their account
+func TestBuildTooltip_AccountPrefix(t *testing.T) {
+	opts := config.Default().Waybar
+	notifications := []cache.CacheEntry{
+		{Account: "work", Repository: "org/api", Title: "Fix login", Reason: "mention"},
+		{Repository: "me/dotfiles", Title: "Bump deps", Reason: "subscribed"},
+	}
+
+	tooltip := buildTooltip(notifications, nil, opts)
+
+	if !strings.Contains(tooltip, "[work] org/api:") {
+		t.Errorf("Expected account prefix in tooltip, got:\n%s", tooltip)
+	}
+	if !strings.Contains(tooltip, " me/dotfiles:") {
+		t.Errorf("Expected unprefixed repository for the implicit account, got:\n%s", tooltip)
+	}
+
+	t.Logf("✓ Tooltip account prefix test passed!")
+}
+
+// TestBuildTooltip_SubjectState tests that the subject state is shown next to the title
+func TestBuildTooltip_SubjectState(t *testing.T) {
+	opts := config.Default().Waybar
+	opts.MaxLineLength = 200
+	notifications := []cache.CacheEntry{
+		{Repository: "org/api", Title: "Fix login", Reason: "review_requested", Subject: &cache.SubjectState{State: "MERGED"}},
+		{Repository: "org/api", Title: "Add cache", Reason: "mention"},
+	}
+
+	tooltip := buildTooltip(notifications, nil, opts)
+
+	if !strings.Contains(tooltip, "Fix login (Review requested) [merged]") {
+		t.Errorf("Expected subject state in tooltip, got:\n%s", tooltip)
+	}
+	if !strings.Contains(tooltip, "Add cache (Mentioned)\n") {
+		t.Errorf("Expected no state suffix without a subject state, got:\n%s", tooltip)
+	}
+
+	t.Logf("✓ Tooltip subject state test passed!")
+}
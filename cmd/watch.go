@@ -0,0 +1,329 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/github"
+	"github.com/bnema/gh-notify/internal/logger"
+	"github.com/bnema/gh-notify/internal/notifier"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// Delay before retrying after a failed notification poll
+	watchRetryInterval = 2 * time.Minute
+	// Sink deliveries waiting for the worker; more are dropped with a warning
+	sinkQueueSize = 64
+	// How long shutdown waits for queued sink deliveries
+	sinkDrainTimeout = 30 * time.Second
+)
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Continuously watch GitHub notifications (daemon mode)",
+	Long: `Run gh-notify as a long-running daemon instead of a one-shot sync.
+
+watch keeps a single authenticated GitHub client in memory, and polls for
+notifications at the interval GitHub requests via the X-Poll-Interval response
+header (60s by default). Stars are fetched on an internal schedule
+(sync.star_fetch_interval, hourly by default).
+
+Each poll reloads the cache under the cache lock and saves it right away, so
+changes made meanwhile by read, mute, clear or sync are kept. SIGINT and
+SIGTERM stop the daemon cleanly.`,
+	RunE: runWatch,
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&noNotify, "no-notify", false, "skip desktop notifications, just update cache")
+	watchCmd.Flags().DurationVar(&since, "since", 0, "only check notifications updated since duration ago (e.g., 1h, 30m)")
+	watchCmd.Flags().BoolVar(&excludeStars, "exclude-stars", false, "skip star tracking (stars are tracked by default)")
+}
+
+// watcher holds the state shared across polls of the watch daemon. The cache
+// is not kept between polls: each poll reloads it under the cache lock, so
+// changes made meanwhile by read, mute, clear or a sync are kept.
+type watcher struct {
+	account  account
+	client   github.GitHubClientInterface
+	notifier *notifier.Notifier // nil when desktop notifications are disabled
+	// deliveries sends new events to the sinks off the poll loop
+	deliveries *deliveryQueue
+	// lastEventSync is when stars were last fetched, to resume their schedule
+	lastEventSync time.Time
+}
+
+// deliveryQueue runs sink deliveries one at a time on a single worker, so slow
+// sinks neither hold up polls nor pile up goroutines
+type deliveryQueue struct {
+	jobs chan func()
+	done chan struct{}
+}
+
+func newDeliveryQueue(size int) *deliveryQueue {
+	q := &deliveryQueue{
+		jobs: make(chan func(), size),
+		done: make(chan struct{}),
+	}
+	go func() {
+		defer close(q.done)
+		for job := range q.jobs {
+			job()
+		}
+	}()
+	return q
+}
+
+// add queues a delivery, dropping it when the queue is full
+func (q *deliveryQueue) add(job func()) {
+	select {
+	case q.jobs <- job:
+	default:
+		logger.Warn().Msg("Sink delivery queue full, dropping events")
+	}
+}
+
+// drain stops taking deliveries and waits up to timeout for the queued ones.
+// Nothing may be added afterwards.
+func (q *deliveryQueue) drain(timeout time.Duration) {
+	close(q.jobs)
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-q.done:
+	case <-timer.C:
+		logger.Warn().Dur("timeout", timeout).Msg("Gave up waiting for sink deliveries")
+	}
+}
+
+func runWatch(cmd *cobra.Command, args []string) error {
+	// Initialize logger
+	logger.Init(verbose)
+
+	// Flags take precedence over the config file
+	configDefault(cmd.Flags(), "since", &since, cfg.Sync.Since)
+	configDefault(cmd.Flags(), "exclude-stars", &excludeStars, cfg.Sync.ExcludeStars)
+	configDefault(cmd.Flags(), "no-notify", &noNotify, !cfg.Notifier.Enabled)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info().Str("cache_dir", cacheDir).Msg("Starting watch daemon")
+
+	accts, err := accounts()
+	if err != nil {
+		return err
+	}
+
+	// Set up every account before polling, so a bad account fails at startup
+	deliveries := newDeliveryQueue(sinkQueueSize)
+	var watchers []*watcher
+	for _, acct := range accts {
+		w, err := newWatcher(acct)
+		if err != nil {
+			return err
+		}
+		w.deliveries = deliveries
+		watchers = append(watchers, w)
+	}
+
+	// Each account polls on its own schedule
+	errs := make(chan error, len(watchers))
+	for _, w := range watchers {
+		go func(w *watcher) {
+			errs <- w.run(ctx)
+		}(w)
+	}
+
+	var runErr error
+	for range watchers {
+		if err := <-errs; err != nil {
+			runErr = err
+		}
+	}
+
+	// Every poll loop has stopped, so nothing is queued after this
+	deliveries.drain(sinkDrainTimeout)
+
+	return runErr
+}
+
+// newWatcher checks the account's cache and authenticates its client once for
+// the lifetime of the daemon
+func newWatcher(acct account) (*watcher, error) {
+	c, err := acct.loadCache()
+	if err != nil {
+		return nil, err
+	}
+
+	ghClient, err := acct.newClient()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ghClient.TestAuth(); err != nil {
+		return nil, fmt.Errorf("GitHub authentication failed for %s: %w", acct.label(), err)
+	}
+
+	w := &watcher{
+		account:       acct,
+		client:        ghClient,
+		lastEventSync: c.LastEventSync,
+	}
+
+	if !noNotify {
+		backend, err := notificationBackend()
+		if err != nil {
+			return nil, err
+		}
+		dispatcher := &watchDispatcher{backend: backend, threads: &diskThreadActions{acct: acct}}
+		if w.notifier, err = newNotifier(acct, ghClient, dispatcher); err != nil {
+			return nil, err
+		}
+	}
+
+	return w, nil
+}
+
+// run polls notifications and stars until ctx is cancelled
+func (w *watcher) run(ctx context.Context) error {
+	pollTimer := time.NewTimer(0)
+	defer pollTimer.Stop()
+
+	// Stars follow their own schedule, resuming from the last persisted fetch.
+	// A nil channel never fires, which disables star polling.
+	var starTimer *time.Timer
+	var starC <-chan time.Time
+	if !excludeStars {
+		nextStarFetch := time.Until(w.lastEventSync.Add(cfg.Sync.StarFetchInterval))
+		if w.lastEventSync.IsZero() || nextStarFetch < 0 {
+			nextStarFetch = 0
+		}
+		starTimer = time.NewTimer(nextStarFetch)
+		defer starTimer.Stop()
+		starC = starTimer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info().Str("account", w.account.name).Msg("Shutting down watch daemon")
+			return nil
+
+		case <-pollTimer.C:
+			pollTimer.Reset(w.pollNotifications())
+
+		case <-starC:
+			w.pollStars()
+			starTimer.Reset(cfg.Sync.StarFetchInterval)
+		}
+	}
+}
+
+// update takes the cache lock and reloads the cache, so changes made since the
+// last poll by other commands are kept, then applies change and saves the
+// cache before unlocking
+func (w *watcher) update(change func(c *cache.Cache) error) error {
+	lock, err := w.account.lockCache()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Unlock() }()
+
+	c, err := w.account.loadCache()
+	if err != nil {
+		return err
+	}
+
+	if err := change(c); err != nil {
+		return err
+	}
+
+	if err := w.account.saveCache(c); err != nil {
+		return err
+	}
+	logger.Debug().Msg("Cache saved")
+
+	return nil
+}
+
+// pollNotifications fetches notifications once and returns the delay until the next poll
+func (w *watcher) pollNotifications() time.Duration {
+	var newNotifications []cache.CacheEntry
+	var pollInterval time.Duration
+
+	err := w.update(func(c *cache.Cache) error {
+		var err error
+		newNotifications, pollInterval, err = syncNotifications(w.client, c)
+		if err != nil {
+			return err
+		}
+
+		// Held alerts go out as a catch-up on the first poll after quiet hours
+		if w.notifier != nil && (len(newNotifications) > 0 || c.HasHeldAlerts() || cfg.Notifier.Summary) {
+			deliverAlerts(w.notifier, c, newNotifications, nil, time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Str("account", w.account.name).
+			Str("error_type", github.ClassifyGitHubError(err)).
+			Dur("retry_in", watchRetryInterval).
+			Msg("Notification poll failed")
+		return watchRetryInterval
+	}
+
+	// Webhook retries can take a while, so sinks must not hold up the loop
+	w.deliver(newNotifications, nil)
+
+	logger.Debug().Dur("next_poll", pollInterval).Msg("Notification poll completed")
+
+	return pollInterval
+}
+
+// pollStars fetches new star events and alerts on them
+func (w *watcher) pollStars() {
+	var newStars []cache.StarEvent
+
+	err := w.update(func(c *cache.Cache) error {
+		var err error
+		newStars, err = syncStars(w.client, c)
+		if err != nil {
+			return err
+		}
+
+		if len(newStars) > 0 && w.notifier != nil {
+			deliverAlerts(w.notifier, c, nil, newStars, time.Now())
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error().
+			Err(err).
+			Str("account", w.account.name).
+			Str("error_type", github.ClassifyGitHubError(err)).
+			Msg("Star poll failed")
+		return
+	}
+
+	w.deliver(nil, newStars)
+}
+
+// deliver queues new notifications and stars for the sinks
+func (w *watcher) deliver(notifications []cache.CacheEntry, stars []cache.StarEvent) {
+	if len(notifications) == 0 && len(stars) == 0 {
+		return
+	}
+	w.deliveries.add(func() {
+		eventSinks.Deliver(w.account.name, notifications, stars)
+	})
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+)
+
+// TestWatcherUpdate_KeepsConcurrentChanges tests that a poll reloads the cache
+// instead of overwriting changes made by other commands since the last poll
+func TestWatcherUpdate_KeepsConcurrentChanges(t *testing.T) {
+	setAccountsConfig(t, nil, "")
+
+	accts, err := accounts()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	w := &watcher{account: accts[0]}
+	now := time.Now().UTC()
+
+	// First poll caches two threads
+	err = w.update(func(c *cache.Cache) error {
+		c.AddNotifications([]cache.CacheEntry{
+			{ID: "1", Timestamp: now, UpdatedAt: now},
+			{ID: "2", Timestamp: now, UpdatedAt: now},
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Meanwhile, read and mute change the cache on disk
+	c, err := w.account.loadCache()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	c.RemoveNotifications("1")
+	c.Mute("2")
+	if err := w.account.saveCache(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The next poll sees and keeps those changes
+	err = w.update(func(c *cache.Cache) error {
+		if len(c.GetNotifications()) != 1 || !c.IsMuted("2") {
+			t.Errorf("Expected the poll to start from the changed cache, got %+v", c.GetNotifications())
+		}
+		c.LastModified = "later"
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	c, err = w.account.loadCache()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(c.GetNotifications()) != 1 || !c.IsMuted("2") || c.LastModified != "later" {
+		t.Errorf("Expected concurrent and poll changes to be saved, got %+v", c)
+	}
+
+	t.Logf("✓ Watcher update test passed!")
+}
+
+// TestDeliveryQueue tests that queued sink deliveries run before drain returns
+// and that a full queue drops deliveries instead of blocking the poll
+func TestDeliveryQueue(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var ran atomic.Int32
+
+	q := newDeliveryQueue(1)
+	q.add(func() { close(started); <-release; ran.Add(1) }) // Taken by the worker
+	<-started
+	q.add(func() { ran.Add(1) }) // Queued
+	q.add(func() { ran.Add(1) }) // Dropped
+
+	close(release)
+	q.drain(time.Second)
+
+	if ran.Load() != 2 {
+		t.Errorf("Expected 2 deliveries to run, got %d", ran.Load())
+	}
+
+	t.Logf("✓ Delivery queue test passed!")
+}
@@ -2,11 +2,15 @@ package cache
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
+	"strings"
 	"time"
+
+	"github.com/bnema/gh-notify/internal/logger"
 )
 
 type CacheEntry struct {
@@ -19,6 +23,68 @@ type CacheEntry struct {
 	WebURL     string    `json:"web_url"`
 	Timestamp  time.Time `json:"timestamp"`
 	UpdatedAt  time.Time `json:"updated_at"`
+	// LatestCommentURL is the API URL of the thread's latest comment, if any
+	LatestCommentURL string `json:"latest_comment_url,omitempty"`
+	// ResolvedFrom is the API URL whose html_url was stored in WebURL, or
+	// "discussion:" and the title a discussion was found by. It is empty while
+	// WebURL is still derived from the subject URL.
+	ResolvedFrom string `json:"resolved_from,omitempty"`
+	// Set by filtering rules: Silent entries never raise desktop alerts, and
+	// Urgency overrides the notifier urgency for this entry
+	Silent  bool   `json:"silent,omitempty"`
+	Urgency string `json:"urgency,omitempty"`
+	// Subject is the live state of an issue or pull request subject, if known
+	Subject *SubjectState `json:"subject,omitempty"`
+	// Account names the configured account the entry belongs to. It is set
+	// when the caches of several accounts are merged and is not persisted.
+	Account string `json:"-"`
+}
+
+// SubjectState is the state of an issue or pull request as reported by the
+// GraphQL API. ReviewDecision and CheckStatus are only set for pull requests.
+type SubjectState struct {
+	State          string `json:"state"`                     // OPEN, CLOSED or MERGED
+	Draft          bool   `json:"draft,omitempty"`           // pull request is a draft
+	ReviewDecision string `json:"review_decision,omitempty"` // APPROVED, CHANGES_REQUESTED or REVIEW_REQUIRED
+	CheckStatus    string `json:"check_status,omitempty"`    // status check rollup of the head commit
+}
+
+// Label returns a short human readable summary such as "merged" or
+// "open, approved, ci failing", or an empty string for a nil state
+func (s *SubjectState) Label() string {
+	if s == nil || s.State == "" {
+		return ""
+	}
+
+	// Reviews and checks only matter while the subject is still open
+	if s.State != "OPEN" {
+		return strings.ToLower(s.State)
+	}
+
+	parts := []string{"open"}
+	if s.Draft {
+		parts[0] = "draft"
+	}
+
+	switch s.ReviewDecision {
+	case "APPROVED":
+		parts = append(parts, "approved")
+	case "CHANGES_REQUESTED":
+		parts = append(parts, "changes requested")
+	case "REVIEW_REQUIRED":
+		parts = append(parts, "review required")
+	}
+
+	switch s.CheckStatus {
+	case "SUCCESS":
+		parts = append(parts, "ci passing")
+	case "FAILURE", "ERROR":
+		parts = append(parts, "ci failing")
+	case "PENDING", "EXPECTED":
+		parts = append(parts, "ci pending")
+	}
+
+	return strings.Join(parts, ", ")
 }
 
 // StarEvent represents a star event for caching
@@ -42,12 +108,35 @@ type Cache struct {
 	Stars         []StarEvent  `json:"stars"`
 	LastEventSync time.Time    `json:"last_event_sync"` // Track last sync for rate limiting
 	MaxEntries    int          `json:"max_entries"`
+	// LastModified is the Last-Modified header of the last notifications
+	// response, sent back as If-Modified-Since for conditional polling
+	LastModified string `json:"last_modified,omitempty"`
+	// Muted holds thread IDs muted with 'gh-notify mute'. They never raise
+	// desktop alerts, even if GitHub keeps them unread. They are forgotten once
+	// the thread has left both the cache and the history.
+	Muted []string `json:"muted,omitempty"`
+	// HeldNotifications and HeldStars hold the IDs of alerts suppressed during
+	// quiet hours, sent as one catch-up summary once quiet hours end
+	HeldNotifications []string `json:"held_notifications,omitempty"`
+	HeldStars         []string `json:"held_stars,omitempty"`
+	// SummaryID is the desktop notification ID of the persistent unread
+	// summary, replaced in place on each sync, and SummaryCount the unread
+	// count it shows. Both are zero while no summary is shown.
+	SummaryID    uint32 `json:"summary_id,omitempty"`
+	SummaryCount int    `json:"summary_count,omitempty"`
+
+	// history keeps threads after they leave the unread list. It is saved
+	// to its own file by Save.
+	history *History
+	// migratedFrom is the version the cache file was migrated from on load,
+	// so Save backs the file up before replacing it
+	migratedFrom string
 }
 
 const (
 	DefaultMaxEntries = 500
 	MaxAge            = 30 * 24 * time.Hour // 30 days
-	CacheVersion      = "1.0"
+	CacheVersion      = "1.1"               // Bump with a step in migrations when the file format changes
 )
 
 func New(cacheDir string) *Cache {
@@ -61,6 +150,11 @@ func New(cacheDir string) *Cache {
 	}
 }
 
+// ErrCorrupt is wrapped by Load when the cache file cannot be parsed. The bad
+// file has been moved aside and the cache is left empty, so callers can warn
+// and carry on with a fresh cache.
+var ErrCorrupt = errors.New("cache file is corrupt")
+
 func (c *Cache) getCacheFile(cacheDir string) string {
 	return filepath.Join(cacheDir, "notifications.json")
 }
@@ -74,6 +168,12 @@ func (c *Cache) Load(cacheDir string) error {
 		return fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
+	history, err := loadHistory(cacheDir)
+	if err != nil {
+		return err
+	}
+	c.history = history
+
 	cacheFile := c.getCacheFile(cacheDir)
 
 	data, err := os.ReadFile(cacheFile)
@@ -85,8 +185,21 @@ func (c *Cache) Load(cacheDir string) error {
 		return fmt.Errorf("failed to read cache file: %w", err)
 	}
 
+	data, from, err := migrate(cacheFile, data)
+	if err != nil {
+		if errors.Is(err, ErrCorrupt) {
+			return c.quarantine(cacheDir, err)
+		}
+		return fmt.Errorf("failed to load cache file: %w", err)
+	}
+
 	if err := json.Unmarshal(data, c); err != nil {
-		return fmt.Errorf("failed to unmarshal cache: %w", err)
+		return c.quarantine(cacheDir, err)
+	}
+
+	if from != "" {
+		logger.Debug().Str("from", from).Str("to", CacheVersion).Str("file", cacheFile).Msg("Cache migrated in memory")
+		c.migratedFrom = from
 	}
 
 	// Ensure MaxEntries is set
@@ -97,13 +210,32 @@ func (c *Cache) Load(cacheDir string) error {
 	return nil
 }
 
+// quarantine moves a cache file that cannot be parsed aside, keeping it for
+// inspection, and resets c to an empty cache
+func (c *Cache) quarantine(cacheDir string, err error) error {
+	history := c.history
+	*c = *New(cacheDir)
+	c.history = history
+
+	cacheFile := c.getCacheFile(cacheDir)
+	quarantined := fmt.Sprintf("%s.corrupt-%s", cacheFile, time.Now().UTC().Format("20060102T150405Z"))
+	if renameErr := os.Rename(cacheFile, quarantined); renameErr != nil {
+		return fmt.Errorf("failed to unmarshal cache: %w (and failed to move it aside: %v)", err, renameErr)
+	}
+
+	if errors.Is(err, ErrCorrupt) {
+		return fmt.Errorf("%w, moved to %s", err, quarantined)
+	}
+	return fmt.Errorf("%w, moved to %s: %v", ErrCorrupt, quarantined, err)
+}
+
 func (c *Cache) Save(cacheDir string) error {
 	if err := c.ensureCacheDir(cacheDir); err != nil {
 		return fmt.Errorf("failed to create cache directory: %w", err)
 	}
 
 	// Cleanup before saving
-	c.cleanup()
+	c.cleanup(c.MaxEntries)
 
 	data, err := json.MarshalIndent(c, "", "  ")
 	if err != nil {
@@ -111,14 +243,82 @@ func (c *Cache) Save(cacheDir string) error {
 	}
 
 	cacheFile := c.getCacheFile(cacheDir)
-	if err := os.WriteFile(cacheFile, data, 0644); err != nil {
+	if c.migratedFrom != "" {
+		if err := backupCacheFile(cacheFile, c.migratedFrom); err != nil {
+			return err
+		}
+		logger.Info().Str("from", c.migratedFrom).Str("to", CacheVersion).Str("file", cacheFile).Msg("Cache migrated")
+	}
+
+	if err := writeFileAtomic(cacheFile, data); err != nil {
 		return fmt.Errorf("failed to write cache file: %w", err)
 	}
+	c.migratedFrom = ""
+
+	if c.history != nil {
+		if err := c.history.save(cacheDir); err != nil {
+			return err
+		}
+	}
 
 	return nil
 }
 
+// writeFileAtomic writes data to a temporary file next to path, syncs it and
+// renames it over path, so a crash leaves either the old or the new file
+// but never a partial one
+func writeFileAtomic(path string, data []byte) (err error) {
+	dir := filepath.Dir(path)
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmp.Name())
+		}
+	}()
+
+	if _, err = tmp.Write(data); err != nil {
+		return err
+	}
+	if err = tmp.Chmod(0644); err != nil {
+		return err
+	}
+	if err = tmp.Sync(); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	// Sync the directory so the rename itself survives a crash
+	d, err := os.Open(dir)
+	if err != nil {
+		return nil
+	}
+	defer func() { _ = d.Close() }()
+	_ = d.Sync()
+
+	return nil
+}
+
+// AddNotifications replaces the cached notifications with GitHub's whole
+// unread set and returns the genuinely new ones
 func (c *Cache) AddNotifications(notifications []CacheEntry) []CacheEntry {
+	return c.ReplaceNotifications(notifications, true)
+}
+
+// ReplaceNotifications replaces the cached notifications and returns the
+// genuinely new ones. complete tells whether notifications is GitHub's whole
+// unread set; when it was filtered (e.g. sync --since), threads missing from it
+// may still be unread, so the history does not mark them as gone.
+func (c *Cache) ReplaceNotifications(notifications []CacheEntry, complete bool) []CacheEntry {
 	c.LastSync = time.Now().UTC()
 
 	// Create map of existing notification IDs
@@ -138,10 +338,82 @@ func (c *Cache) AddNotifications(notifications []CacheEntry) []CacheEntry {
 	// Replace entire cache with current unread notifications from GitHub
 	// This automatically removes notifications that were read (not in incoming list)
 	c.Notifications = notifications
+	c.historyStore().observe(notifications, complete, c.LastSync)
 
 	return newNotifications
 }
 
+// MergeNotifications adds or updates notifications without evicting cached
+// entries missing from the incoming list. Use it when the incoming list is
+// known to be incomplete (e.g. a truncated fetch), so unseen threads are not
+// mistaken for read ones. It returns only the genuinely new notifications.
+func (c *Cache) MergeNotifications(notifications []CacheEntry) []CacheEntry {
+	c.LastSync = time.Now().UTC()
+
+	// Map existing notification IDs to their position
+	existing := make(map[string]int)
+	for i, entry := range c.Notifications {
+		existing[entry.ID] = i
+	}
+
+	var newNotifications []CacheEntry
+	for _, notification := range notifications {
+		if i, ok := existing[notification.ID]; ok {
+			c.Notifications[i] = notification
+			continue
+		}
+		newNotifications = append(newNotifications, notification)
+		c.Notifications = append(c.Notifications, notification)
+	}
+	c.historyStore().observe(notifications, false, c.LastSync)
+
+	return newNotifications
+}
+
+// RemoveNotifications drops the notifications with the given thread IDs, e.g.
+// after they were marked as read. It returns the number of entries removed.
+func (c *Cache) RemoveNotifications(ids ...string) int {
+	remove := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		remove[id] = true
+	}
+
+	kept := c.Notifications[:0]
+	for _, entry := range c.Notifications {
+		if !remove[entry.ID] {
+			kept = append(kept, entry)
+		}
+	}
+
+	removed := len(c.Notifications) - len(kept)
+	c.Notifications = kept
+	c.historyStore().markRead(ids, time.Now().UTC())
+
+	return removed
+}
+
+// RemoveReadBefore drops the notifications last updated at or before the given
+// time, optionally limited to one repository, mirroring GitHub's last_read_at
+// semantics. It returns the number of entries removed.
+func (c *Cache) RemoveReadBefore(repository string, before time.Time) int {
+	var read []string
+	kept := c.Notifications[:0]
+	for _, entry := range c.Notifications {
+		inScope := repository == "" || strings.EqualFold(entry.Repository, repository)
+		if !inScope || entry.UpdatedAt.After(before) {
+			kept = append(kept, entry)
+			continue
+		}
+		read = append(read, entry.ID)
+	}
+
+	removed := len(c.Notifications) - len(kept)
+	c.Notifications = kept
+	c.historyStore().markRead(read, time.Now().UTC())
+
+	return removed
+}
+
 // AddStarEvents adds star events to the cache and returns only new ones
 func (c *Cache) AddStarEvents(starEvents []StarEvent) []StarEvent {
 	// Create map of existing star IDs
@@ -162,7 +434,9 @@ func (c *Cache) AddStarEvents(starEvents []StarEvent) []StarEvent {
 	return newStarEvents
 }
 
-func (c *Cache) cleanup() {
+// cleanup drops expired notifications and stars and keeps at most maxEntries
+// of each, newest first; 0 keeps them all
+func (c *Cache) cleanup(maxEntries int) {
 	now := time.Now().UTC()
 
 	// Cleanup notifications
@@ -181,8 +455,8 @@ func (c *Cache) cleanup() {
 	})
 
 	// Apply max entries limit
-	if len(validEntries) > c.MaxEntries {
-		validEntries = validEntries[:c.MaxEntries]
+	if maxEntries > 0 && len(validEntries) > maxEntries {
+		validEntries = validEntries[:maxEntries]
 	}
 
 	c.Notifications = validEntries
@@ -201,11 +475,29 @@ func (c *Cache) cleanup() {
 	})
 
 	// Apply max entries limit for stars (reuse MaxEntries)
-	if len(validStars) > c.MaxEntries {
-		validStars = validStars[:c.MaxEntries]
+	if maxEntries > 0 && len(validStars) > maxEntries {
+		validStars = validStars[:maxEntries]
 	}
 
 	c.Stars = validStars
+
+	// Forget muted threads once they are neither cached nor in the history
+	known := make(map[string]bool, len(c.Notifications))
+	for _, entry := range c.Notifications {
+		known[entry.ID] = true
+	}
+	if c.history != nil {
+		for _, record := range c.history.Records {
+			known[record.ID] = true
+		}
+	}
+	var validMuted []string
+	for _, id := range c.Muted {
+		if known[id] {
+			validMuted = append(validMuted, id)
+		}
+	}
+	c.Muted = validMuted
 }
 
 func (c *Cache) GetNotifications() []CacheEntry {
@@ -215,6 +507,31 @@ func (c *Cache) GetNotifications() []CacheEntry {
 	return result
 }
 
+// History returns a copy of the thread history, including read threads
+func (c *Cache) History() []HistoryRecord {
+	records := c.historyStore().Records
+	result := make([]HistoryRecord, len(records))
+	copy(result, records)
+	return result
+}
+
+// SetHistoryRetention sets how long read and disappeared threads stay in the
+// history. Zero keeps DefaultHistoryRetention.
+func (c *Cache) SetHistoryRetention(retention time.Duration) {
+	if retention > 0 {
+		c.historyStore().retention = retention
+	}
+}
+
+// historyStore returns the history, starting an empty one for caches that
+// were never loaded
+func (c *Cache) historyStore() *History {
+	if c.history == nil {
+		c.history = newHistory()
+	}
+	return c.history
+}
+
 // GetStars returns a copy of cached star events
 func (c *Cache) GetStars() []StarEvent {
 	result := make([]StarEvent, len(c.Stars))
@@ -222,11 +539,97 @@ func (c *Cache) GetStars() []StarEvent {
 	return result
 }
 
+// Mute records a muted thread ID. It returns false if it was already muted.
+func (c *Cache) Mute(id string) bool {
+	if c.IsMuted(id) {
+		return false
+	}
+	c.Muted = append(c.Muted, id)
+	return true
+}
+
+// Unmute forgets a muted thread ID. It returns false if it was not muted.
+func (c *Cache) Unmute(id string) bool {
+	for i, muted := range c.Muted {
+		if muted == id {
+			c.Muted = append(c.Muted[:i], c.Muted[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// IsMuted reports whether a thread ID was muted
+func (c *Cache) IsMuted(id string) bool {
+	return containsID(c.Muted, id)
+}
+
+// HoldAlerts records notifications and star events whose alerts were
+// suppressed during quiet hours
+func (c *Cache) HoldAlerts(notifications []CacheEntry, stars []StarEvent) {
+	for _, entry := range notifications {
+		if !containsID(c.HeldNotifications, entry.ID) {
+			c.HeldNotifications = append(c.HeldNotifications, entry.ID)
+		}
+	}
+	for _, star := range stars {
+		if !containsID(c.HeldStars, star.ID) {
+			c.HeldStars = append(c.HeldStars, star.ID)
+		}
+	}
+}
+
+// TakeHeldAlerts returns the held notifications and star events and forgets
+// them. Held notifications that are no longer cached, because they were read
+// in the meantime, are skipped.
+func (c *Cache) TakeHeldAlerts() ([]CacheEntry, []StarEvent) {
+	var notifications []CacheEntry
+	for _, entry := range c.Notifications {
+		if containsID(c.HeldNotifications, entry.ID) {
+			notifications = append(notifications, entry)
+		}
+	}
+
+	var stars []StarEvent
+	for _, star := range c.Stars {
+		if containsID(c.HeldStars, star.ID) {
+			stars = append(stars, star)
+		}
+	}
+
+	c.HeldNotifications = nil
+	c.HeldStars = nil
+
+	return notifications, stars
+}
+
+// HasHeldAlerts reports whether alerts are waiting for a catch-up summary
+func (c *Cache) HasHeldAlerts() bool {
+	return len(c.HeldNotifications) > 0 || len(c.HeldStars) > 0
+}
+
+func containsID(ids []string, id string) bool {
+	for _, existing := range ids {
+		if existing == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Clear resets cached notifications and sync state. Muted threads are kept,
+// as they record a user decision rather than fetched data, and so is the
+// history.
 func (c *Cache) Clear() {
 	c.Notifications = []CacheEntry{}
 	c.Stars = []StarEvent{}
 	c.LastSync = time.Time{}
 	c.LastEventSync = time.Time{}
+	c.LastModified = ""
+	c.HeldNotifications = nil
+	c.HeldStars = nil
+	c.SummaryID = 0
+	c.SummaryCount = 0
 }
 
 func GetDefaultCacheDir() (string, error) {
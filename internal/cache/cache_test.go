@@ -154,7 +154,7 @@ func TestCleanup_7DayStarRetention(t *testing.T) {
 	c.AddStarEvents(stars)
 
 	// Run cleanup
-	c.cleanup()
+	c.cleanup(c.MaxEntries)
 
 	// Should only have 2 stars (6 days old and recent)
 	if len(c.Stars) != 2 {
@@ -212,7 +212,7 @@ func TestCleanup_MaxEntriesForStars(t *testing.T) {
 	c.AddStarEvents(stars)
 
 	// Run cleanup
-	c.cleanup()
+	c.cleanup(c.MaxEntries)
 
 	// Should only have 3 stars (MaxEntries)
 	if len(c.Stars) != 3 {
@@ -233,3 +233,203 @@ func TestCleanup_MaxEntriesForStars(t *testing.T) {
 
 	t.Logf("✓ MaxEntries limit test passed!")
 }
+
+// TestAddNotifications_ReplacesCache tests that notifications missing from a complete list are evicted
+func TestAddNotifications_ReplacesCache(t *testing.T) {
+	c := New("")
+
+	c.AddNotifications([]CacheEntry{{ID: "1"}, {ID: "2"}})
+	newEntries := c.AddNotifications([]CacheEntry{{ID: "2"}, {ID: "3"}})
+
+	if len(newEntries) != 1 || newEntries[0].ID != "3" {
+		t.Errorf("Expected only '3' to be new, got %+v", newEntries)
+	}
+
+	if len(c.Notifications) != 2 {
+		t.Errorf("Expected read notification '1' to be evicted, got %d entries", len(c.Notifications))
+	}
+
+	t.Logf("✓ Replace cache test passed!")
+}
+
+// TestMergeNotifications_KeepsUnseen tests that a partial list does not evict unseen threads
+func TestMergeNotifications_KeepsUnseen(t *testing.T) {
+	c := New("")
+
+	c.AddNotifications([]CacheEntry{{ID: "1", Title: "old"}, {ID: "2"}})
+	newEntries := c.MergeNotifications([]CacheEntry{{ID: "1", Title: "updated"}, {ID: "3"}})
+
+	if len(newEntries) != 1 || newEntries[0].ID != "3" {
+		t.Errorf("Expected only '3' to be new, got %+v", newEntries)
+	}
+
+	if len(c.Notifications) != 3 {
+		t.Fatalf("Expected 3 notifications after merge, got %d", len(c.Notifications))
+	}
+
+	for _, entry := range c.Notifications {
+		if entry.ID == "1" && entry.Title != "updated" {
+			t.Errorf("Expected notification '1' to be updated, got title %q", entry.Title)
+		}
+	}
+
+	t.Logf("✓ Merge notifications test passed!")
+}
+
+// TestRemoveNotifications tests removing notifications by thread ID
+func TestRemoveNotifications(t *testing.T) {
+	c := New("")
+	c.AddNotifications([]CacheEntry{{ID: "1"}, {ID: "2"}, {ID: "3"}})
+
+	removed := c.RemoveNotifications("1", "3", "missing")
+
+	if removed != 2 {
+		t.Errorf("Expected 2 notifications removed, got %d", removed)
+	}
+
+	if len(c.Notifications) != 1 || c.Notifications[0].ID != "2" {
+		t.Errorf("Expected only '2' to remain, got %+v", c.Notifications)
+	}
+
+	t.Logf("✓ Remove notifications test passed!")
+}
+
+// TestRemoveReadBefore tests removing notifications by repository and age
+func TestRemoveReadBefore(t *testing.T) {
+	now := time.Now()
+	c := New("")
+	c.AddNotifications([]CacheEntry{
+		{ID: "old", Repository: "owner/repo", UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "new", Repository: "owner/repo", UpdatedAt: now},
+		{ID: "other", Repository: "owner/other", UpdatedAt: now.Add(-48 * time.Hour)},
+	})
+
+	removed := c.RemoveReadBefore("Owner/Repo", now.Add(-24*time.Hour))
+
+	if removed != 1 {
+		t.Errorf("Expected 1 notification removed, got %d", removed)
+	}
+
+	remaining := make(map[string]bool)
+	for _, entry := range c.Notifications {
+		remaining[entry.ID] = true
+	}
+	if remaining["old"] || !remaining["new"] || !remaining["other"] {
+		t.Errorf("Unexpected remaining notifications: %+v", c.Notifications)
+	}
+
+	// Without a repository every old enough notification goes
+	if removed := c.RemoveReadBefore("", now); removed != 2 {
+		t.Errorf("Expected 2 notifications removed, got %d", removed)
+	}
+
+	t.Logf("✓ Remove read before test passed!")
+}
+
+// TestMute tests remembering muted thread IDs across save, load and clear
+func TestMute(t *testing.T) {
+	tmpDir := t.TempDir()
+	c := New(tmpDir)
+	c.AddNotifications([]CacheEntry{{ID: "1", Timestamp: time.Now()}})
+
+	if !c.Mute("1") {
+		t.Error("Expected first mute to return true")
+	}
+	if c.Mute("1") {
+		t.Error("Expected muting twice to return false")
+	}
+
+	if err := c.Save(tmpDir); err != nil {
+		t.Fatalf("Failed to save cache: %v", err)
+	}
+
+	loaded := New(tmpDir)
+	if err := loaded.Load(tmpDir); err != nil {
+		t.Fatalf("Failed to load cache: %v", err)
+	}
+	if !loaded.IsMuted("1") {
+		t.Error("Expected muted thread to survive save and load")
+	}
+
+	loaded.SummaryID, loaded.SummaryCount = 9, 3
+	loaded.Clear()
+	if !loaded.IsMuted("1") {
+		t.Error("Expected muted thread to survive clear")
+	}
+	if loaded.SummaryID != 0 || loaded.SummaryCount != 0 {
+		t.Errorf("Expected clear to forget the summary, got ID %d and count %d", loaded.SummaryID, loaded.SummaryCount)
+	}
+
+	if !loaded.Unmute("1") || loaded.IsMuted("1") {
+		t.Error("Expected unmute to forget the thread")
+	}
+	if loaded.Unmute("1") {
+		t.Error("Expected unmuting an unknown thread to return false")
+	}
+
+	// Threads that left both the cache and the history are forgotten on save
+	loaded.Mute("1")
+	loaded.Mute("2")
+	if err := loaded.Save(tmpDir); err != nil {
+		t.Fatalf("Failed to save cache: %v", err)
+	}
+	if !loaded.IsMuted("1") || loaded.IsMuted("2") {
+		t.Errorf("Expected only the known thread to stay muted, got %v", loaded.Muted)
+	}
+
+	t.Logf("✓ Mute test passed!")
+}
+
+// TestHoldAlerts tests holding alerts during quiet hours and taking them back
+func TestHoldAlerts(t *testing.T) {
+	c := New("")
+	c.AddNotifications([]CacheEntry{{ID: "1"}, {ID: "2"}, {ID: "3"}})
+	c.AddStarEvents([]StarEvent{{ID: "star-1", StarredAt: time.Now().UTC()}})
+
+	c.HoldAlerts([]CacheEntry{{ID: "1"}, {ID: "2"}}, []StarEvent{{ID: "star-1"}})
+	c.HoldAlerts([]CacheEntry{{ID: "1"}}, nil)
+
+	if !c.HasHeldAlerts() {
+		t.Fatal("Expected held alerts")
+	}
+
+	// Thread '2' was read before quiet hours ended
+	c.RemoveNotifications("2")
+
+	notifications, stars := c.TakeHeldAlerts()
+
+	if len(notifications) != 1 || notifications[0].ID != "1" {
+		t.Errorf("Expected only unread held thread '1', got %+v", notifications)
+	}
+	if len(stars) != 1 || stars[0].ID != "star-1" {
+		t.Errorf("Expected held star 'star-1', got %+v", stars)
+	}
+	if c.HasHeldAlerts() {
+		t.Error("Expected held alerts to be cleared after taking them")
+	}
+
+	t.Logf("✓ Hold alerts test passed!")
+}
+
+// TestSubjectStateLabel tests the human readable subject state summary
+func TestSubjectStateLabel(t *testing.T) {
+	tests := []struct {
+		state    *SubjectState
+		expected string
+	}{
+		{nil, ""},
+		{&SubjectState{State: "MERGED", ReviewDecision: "APPROVED", CheckStatus: "SUCCESS"}, "merged"},
+		{&SubjectState{State: "CLOSED"}, "closed"},
+		{&SubjectState{State: "OPEN", Draft: true}, "draft"},
+		{&SubjectState{State: "OPEN", ReviewDecision: "APPROVED", CheckStatus: "FAILURE"}, "open, approved, ci failing"},
+		{&SubjectState{State: "OPEN", ReviewDecision: "REVIEW_REQUIRED", CheckStatus: "PENDING"}, "open, review required, ci pending"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.state.Label(); got != tt.expected {
+			t.Errorf("Label(%+v) = %q, want %q", tt.state, got, tt.expected)
+		}
+	}
+
+	t.Logf("✓ Subject state label test passed!")
+}
@@ -0,0 +1,257 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/logger"
+)
+
+const (
+	// DefaultHistoryRetention is how long read and disappeared threads stay in
+	// the history
+	DefaultHistoryRetention = 90 * 24 * time.Hour
+	// MaxHistoryRecords caps the JSON history, dropping the oldest closed
+	// threads. The SQLite store has no cap.
+	MaxHistoryRecords = 5000
+	// HistoryVersion is the format of history.json
+	HistoryVersion = "1.0"
+)
+
+// HistoryRecord is the life of one notification thread, kept after the
+// thread leaves the unread cache
+type HistoryRecord struct {
+	ID          string    `json:"id"`
+	Repository  string    `json:"repository"`
+	Title       string    `json:"title"`
+	Reason      string    `json:"reason"` // Latest reason
+	Type        string    `json:"type"`
+	WebURL      string    `json:"web_url,omitempty"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastUpdated time.Time `json:"last_updated"` // GitHub's updated_at
+	// ReadAt is set when the thread was marked as read with gh-notify, and
+	// GoneAt when it left the unread list otherwise, e.g. read on GitHub.
+	// Both are cleared when the thread becomes unread again.
+	ReadAt        *time.Time     `json:"read_at,omitempty"`
+	GoneAt        *time.Time     `json:"gone_at,omitempty"`
+	ReasonChanges []ReasonChange `json:"reason_changes,omitempty"`
+}
+
+// ReasonChange records a thread's reason changing between syncs, e.g. from
+// subscribed to review_requested
+type ReasonChange struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	At   time.Time `json:"at"`
+}
+
+// Status is "unread", "read" or "gone"
+func (r HistoryRecord) Status() string {
+	switch {
+	case r.ReadAt != nil:
+		return "read"
+	case r.GoneAt != nil:
+		return "gone"
+	default:
+		return "unread"
+	}
+}
+
+// ClosedAt is when the thread was read or disappeared, or zero while unread
+func (r HistoryRecord) ClosedAt() time.Time {
+	switch {
+	case r.ReadAt != nil:
+		return *r.ReadAt
+	case r.GoneAt != nil:
+		return *r.GoneAt
+	default:
+		return time.Time{}
+	}
+}
+
+// LastActivity is the latest time the thread was seen, updated or closed
+func (r HistoryRecord) LastActivity() time.Time {
+	latest := r.FirstSeen
+	for _, t := range []time.Time{r.LastUpdated, r.ClosedAt()} {
+		if t.After(latest) {
+			latest = t
+		}
+	}
+	return latest
+}
+
+// History is the store of notification threads seen by syncs, saved to
+// history.json next to the cache. Unlike the cache it keeps read threads,
+// with a retention of its own.
+type History struct {
+	Version string          `json:"version"`
+	Records []HistoryRecord `json:"records"`
+
+	retention time.Duration
+	// maxRecords caps the records, or is zero for no cap
+	maxRecords int
+}
+
+func newHistory() *History {
+	return &History{Version: HistoryVersion, Records: []HistoryRecord{}, retention: DefaultHistoryRetention, maxRecords: MaxHistoryRecords}
+}
+
+func historyFile(cacheDir string) string {
+	return filepath.Join(cacheDir, "history.json")
+}
+
+// loadHistory reads history.json. A missing file gives an empty history, and
+// an unreadable one is moved aside so it cannot break syncs.
+func loadHistory(cacheDir string) (*History, error) {
+	h := newHistory()
+
+	path := historyFile(cacheDir)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return nil, fmt.Errorf("failed to read history file: %w", err)
+	}
+
+	if err := json.Unmarshal(data, h); err != nil {
+		quarantined := fmt.Sprintf("%s.corrupt-%s", path, time.Now().UTC().Format("20060102T150405Z"))
+		if renameErr := os.Rename(path, quarantined); renameErr != nil {
+			return nil, fmt.Errorf("failed to unmarshal history: %w (and failed to move it aside: %v)", err, renameErr)
+		}
+		logger.Warn().Err(err).Str("moved_to", quarantined).Msg("History file is corrupt, starting a new one")
+		return newHistory(), nil
+	}
+	if h.Version != HistoryVersion {
+		return nil, fmt.Errorf("unsupported history format %q (this binary supports %s); upgrade gh-notify or remove %s", h.Version, HistoryVersion, path)
+	}
+	if h.Records == nil {
+		h.Records = []HistoryRecord{}
+	}
+
+	return h, nil
+}
+
+// save prunes the history and writes history.json
+func (h *History) save(cacheDir string) error {
+	h.prune(time.Now().UTC())
+
+	data, err := json.MarshalIndent(h, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+
+	if err := writeFileAtomic(historyFile(cacheDir), data); err != nil {
+		return fmt.Errorf("failed to write history file: %w", err)
+	}
+
+	return nil
+}
+
+// observe records the unread notifications of a sync. When complete is true
+// the list is GitHub's whole unread set, so open threads missing from it are
+// marked as gone.
+func (h *History) observe(notifications []CacheEntry, complete bool, now time.Time) {
+	index := make(map[string]int, len(h.Records))
+	for i, record := range h.Records {
+		index[record.ID] = i
+	}
+
+	seen := make(map[string]bool, len(notifications))
+	for _, entry := range notifications {
+		seen[entry.ID] = true
+
+		i, ok := index[entry.ID]
+		if !ok {
+			index[entry.ID] = len(h.Records)
+			h.Records = append(h.Records, HistoryRecord{
+				ID:          entry.ID,
+				Repository:  entry.Repository,
+				Title:       entry.Title,
+				Reason:      entry.Reason,
+				Type:        entry.Type,
+				WebURL:      entry.WebURL,
+				FirstSeen:   now,
+				LastUpdated: entry.UpdatedAt,
+			})
+			continue
+		}
+
+		record := &h.Records[i]
+		if entry.Reason != record.Reason {
+			record.ReasonChanges = append(record.ReasonChanges, ReasonChange{From: record.Reason, To: entry.Reason, At: now})
+			record.Reason = entry.Reason
+		}
+		record.Repository = entry.Repository
+		record.Title = entry.Title
+		record.Type = entry.Type
+		if entry.WebURL != "" {
+			record.WebURL = entry.WebURL
+		}
+		if entry.UpdatedAt.After(record.LastUpdated) {
+			record.LastUpdated = entry.UpdatedAt
+		}
+		// Unread again, e.g. after new activity on a read thread
+		record.ReadAt, record.GoneAt = nil, nil
+	}
+
+	if !complete {
+		return
+	}
+
+	for i := range h.Records {
+		record := &h.Records[i]
+		if !seen[record.ID] && record.ReadAt == nil && record.GoneAt == nil {
+			gone := now
+			record.GoneAt = &gone
+		}
+	}
+}
+
+// markRead records that threads were marked as read with gh-notify
+func (h *History) markRead(ids []string, now time.Time) {
+	read := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		read[id] = true
+	}
+
+	for i := range h.Records {
+		record := &h.Records[i]
+		if read[record.ID] && record.ReadAt == nil {
+			at := now
+			record.ReadAt = &at
+			record.GoneAt = nil
+		}
+	}
+}
+
+// prune drops threads closed longer ago than the retention, then the oldest
+// closed threads beyond the record cap
+func (h *History) prune(now time.Time) {
+	kept := h.Records[:0]
+	for _, record := range h.Records {
+		closed := record.ClosedAt()
+		if closed.IsZero() || now.Sub(closed) <= h.retention {
+			kept = append(kept, record)
+		}
+	}
+	h.Records = kept
+
+	if h.maxRecords <= 0 || len(h.Records) <= h.maxRecords {
+		return
+	}
+
+	// Unread threads first, then the most recently active
+	sort.SliceStable(h.Records, func(i, j int) bool {
+		a, b := h.Records[i], h.Records[j]
+		if a.ClosedAt().IsZero() != b.ClosedAt().IsZero() {
+			return a.ClosedAt().IsZero()
+		}
+		return a.LastActivity().After(b.LastActivity())
+	})
+	h.Records = h.Records[:h.maxRecords]
+}
@@ -0,0 +1,109 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// recordByID returns the history record of a thread, failing the test when missing
+func recordByID(t *testing.T, c *Cache, id string) HistoryRecord {
+	t.Helper()
+	for _, record := range c.History() {
+		if record.ID == id {
+			return record
+		}
+	}
+	t.Fatalf("Expected thread %s in the history", id)
+	return HistoryRecord{}
+}
+
+// TestHistory_Lifecycle tests first-seen, reason changes, read, gone and reopened threads
+func TestHistory_Lifecycle(t *testing.T) {
+	c := New("")
+	updated := time.Now().UTC().Add(-time.Hour)
+
+	c.AddNotifications([]CacheEntry{
+		{ID: "1", Repository: "owner/repo", Reason: "subscribed", UpdatedAt: updated},
+		{ID: "2", Repository: "owner/repo", Reason: "mention", UpdatedAt: updated},
+		{ID: "3", Repository: "owner/tool", Reason: "assign", UpdatedAt: updated},
+	})
+
+	first := recordByID(t, c, "1")
+	if first.FirstSeen.IsZero() || !first.LastUpdated.Equal(updated) || first.Status() != "unread" {
+		t.Errorf("Unexpected new record: %+v", first)
+	}
+
+	// Thread 1 becomes a review request, 3 is read with gh-notify, 2 is read elsewhere
+	c.RemoveNotifications("3")
+	c.AddNotifications([]CacheEntry{
+		{ID: "1", Repository: "owner/repo", Reason: "review_requested", UpdatedAt: updated.Add(time.Minute)},
+	})
+
+	first = recordByID(t, c, "1")
+	if first.Reason != "review_requested" || len(first.ReasonChanges) != 1 || first.ReasonChanges[0].From != "subscribed" {
+		t.Errorf("Expected a reason change from subscribed, got %+v", first)
+	}
+	if !first.LastUpdated.Equal(updated.Add(time.Minute)) || !first.FirstSeen.Equal(recordByID(t, c, "2").FirstSeen) {
+		t.Errorf("Expected last updated to move and first seen to stay, got %+v", first)
+	}
+	if status := recordByID(t, c, "2").Status(); status != "gone" {
+		t.Errorf("Expected thread 2 to be gone, got %s", status)
+	}
+	if status := recordByID(t, c, "3").Status(); status != "read" {
+		t.Errorf("Expected thread 3 to be read, got %s", status)
+	}
+
+	// A truncated fetch does not mark missing threads as gone
+	c.MergeNotifications([]CacheEntry{{ID: "2", Repository: "owner/repo", Reason: "mention", UpdatedAt: updated.Add(time.Hour)}})
+	if status := recordByID(t, c, "1").Status(); status != "unread" {
+		t.Errorf("Expected thread 1 to stay unread after a partial fetch, got %s", status)
+	}
+	if status := recordByID(t, c, "2").Status(); status != "unread" {
+		t.Errorf("Expected thread 2 to be unread again after new activity, got %s", status)
+	}
+
+	t.Logf("✓ History lifecycle test passed!")
+}
+
+// TestHistory_SaveLoadAndRetention tests that the history survives a reload
+// and that closed threads expire with the retention
+func TestHistory_SaveLoadAndRetention(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+
+	c := New(dir)
+	c.AddNotifications([]CacheEntry{{ID: "1", Repository: "owner/repo", Timestamp: now, UpdatedAt: now}})
+	c.RemoveNotifications("1")
+
+	old := now.Add(-48 * time.Hour)
+	c.history.Records = append(c.history.Records,
+		HistoryRecord{ID: "old", FirstSeen: old, ReadAt: &old},
+		HistoryRecord{ID: "open", FirstSeen: old},
+	)
+	c.SetHistoryRetention(24 * time.Hour)
+
+	if err := c.Save(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	loaded := New(dir)
+	if err := loaded.Load(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	records := loaded.History()
+	if len(records) != 2 {
+		t.Fatalf("Expected the expired thread to be pruned, got %+v", records)
+	}
+	if recordByID(t, loaded, "1").Status() != "read" || recordByID(t, loaded, "open").Status() != "unread" {
+		t.Errorf("Unexpected records after reload: %+v", records)
+	}
+
+	// Clearing the cache keeps the history
+	loaded.Clear()
+	if len(loaded.History()) != 2 {
+		t.Errorf("Expected Clear to keep the history")
+	}
+
+	t.Logf("✓ History persistence test passed!")
+}
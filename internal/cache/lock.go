@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// DefaultLockTimeout is how long commands wait for another gh-notify process
+// to finish with a cache
+const DefaultLockTimeout = 30 * time.Second
+
+// lockRetryInterval is the delay between attempts to take a held lock
+const lockRetryInterval = 100 * time.Millisecond
+
+// ErrLocked is returned by Lock when another process holds the lock past the
+// timeout
+var ErrLocked = errors.New("cache is locked by another gh-notify process")
+
+// FileLock is an advisory lock on a cache directory. Processes that load,
+// modify and save a cache hold it for the whole sequence, so concurrent runs
+// cannot clobber each other's changes.
+type FileLock struct {
+	file *os.File
+}
+
+// Lock takes an exclusive flock on the lock file in cacheDir, waiting up to
+// timeout for other holders to release it
+func Lock(cacheDir string, timeout time.Duration) (*FileLock, error) {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	file, err := os.OpenFile(filepath.Join(cacheDir, "notifications.lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache lock: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+		if err == nil {
+			return &FileLock{file: file}, nil
+		}
+		if !errors.Is(err, syscall.EWOULDBLOCK) && !errors.Is(err, syscall.EINTR) {
+			_ = file.Close()
+			return nil, fmt.Errorf("failed to lock cache: %w", err)
+		}
+		if time.Now().After(deadline) {
+			_ = file.Close()
+			return nil, fmt.Errorf("%w (waited %v)", ErrLocked, timeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// Unlock releases the lock. Unlocking a nil lock does nothing.
+func (l *FileLock) Unlock() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+
+	// Closing the file releases the flock
+	err := l.file.Close()
+	l.file = nil
+	if err != nil {
+		return fmt.Errorf("failed to release cache lock: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestLock tests that a held lock blocks others until released
+func TestLock(t *testing.T) {
+	dir := t.TempDir()
+
+	lock, err := Lock(dir, time.Second)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// flock locks belong to the open file, so a second lock conflicts even
+	// within one process
+	if _, err := Lock(dir, 150*time.Millisecond); !errors.Is(err, ErrLocked) {
+		t.Fatalf("Expected ErrLocked while held, got %v", err)
+	}
+
+	released := make(chan error, 1)
+	go func() {
+		second, err := Lock(dir, 5*time.Second)
+		if err == nil {
+			err = second.Unlock()
+		}
+		released <- err
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	if err := lock.Unlock(); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := <-released; err != nil {
+		t.Errorf("Expected the waiting lock to be taken after release, got %v", err)
+	}
+
+	var none *FileLock
+	if err := none.Unlock(); err != nil {
+		t.Errorf("Expected unlocking nil to do nothing, got %v", err)
+	}
+
+	t.Logf("✓ Cache lock test passed!")
+}
+
+// TestSave_Atomic tests that saving replaces the file without leaving temporary files
+func TestSave_Atomic(t *testing.T) {
+	dir := t.TempDir()
+
+	c := New(dir)
+	c.AddNotifications([]CacheEntry{{ID: "1", Repository: "owner/repo", Timestamp: time.Now().UTC(), UpdatedAt: time.Now().UTC()}})
+	if err := c.Save(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	c.AddNotifications([]CacheEntry{{ID: "2", Repository: "owner/repo", Timestamp: time.Now().UTC(), UpdatedAt: time.Now().UTC()}})
+	if err := c.Save(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	files, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to read cache directory: %v", err)
+	}
+	for _, file := range files {
+		if strings.HasSuffix(file.Name(), ".tmp") {
+			t.Errorf("Expected no temporary files, got %s", file.Name())
+		}
+	}
+
+	loaded := New(dir)
+	if err := loaded.Load(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(loaded.Notifications) != 1 || loaded.Notifications[0].ID != "2" {
+		t.Errorf("Expected the second save to be loaded, got %+v", loaded.Notifications)
+	}
+
+	t.Logf("✓ Atomic save test passed!")
+}
+
+// TestLoad_CorruptQuarantined tests that a corrupt cache is moved aside and the next load starts fresh
+func TestLoad_CorruptQuarantined(t *testing.T) {
+	dir := t.TempDir()
+	cacheFile := filepath.Join(dir, "notifications.json")
+	if err := os.WriteFile(cacheFile, []byte(`{"notifications": [{"id": "1"`), 0644); err != nil {
+		t.Fatalf("Failed to write cache file: %v", err)
+	}
+
+	c := New(dir)
+	err := c.Load(dir)
+	if !errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Expected ErrCorrupt, got %v", err)
+	}
+	if len(c.Notifications) != 0 || c.MaxEntries != DefaultMaxEntries {
+		t.Errorf("Expected an empty cache after a corrupt load, got %+v", c)
+	}
+
+	matches, _ := filepath.Glob(cacheFile + ".corrupt-*")
+	if len(matches) != 1 || !strings.Contains(err.Error(), matches[0]) {
+		t.Errorf("Expected the corrupt file to be quarantined and named in %q, got %v", err, matches)
+	}
+
+	if err := New(dir).Load(dir); err != nil {
+		t.Errorf("Expected the next load to start fresh, got %v", err)
+	}
+
+	t.Logf("✓ Corrupt cache quarantine test passed!")
+}
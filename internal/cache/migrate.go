@@ -0,0 +1,141 @@
+package cache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ErrNewerVersion is wrapped by Load when the cache file was written by a
+// newer gh-notify whose format this binary does not know
+var ErrNewerVersion = errors.New("cache was written by a newer version of gh-notify")
+
+// migration upgrades a decoded cache file by one version
+type migration struct {
+	to      string
+	migrate func(doc map[string]interface{}) error
+}
+
+// migrations maps each old version to the step that upgrades it. Every
+// change to the file format bumps CacheVersion and adds a step here, so old
+// files are upgraded one version at a time.
+var migrations = map[string]migration{
+	"1.0": {to: "1.1", migrate: migrateV1_0},
+}
+
+// migrateV1_0 replaces null lists, written by 1.0 after an empty sync, with
+// empty ones and fills in a missing max_entries
+func migrateV1_0(doc map[string]interface{}) error {
+	for _, key := range []string{"notifications", "stars"} {
+		if doc[key] == nil {
+			doc[key] = []interface{}{}
+		}
+	}
+	if maxEntries, _ := doc["max_entries"].(float64); maxEntries <= 0 {
+		doc["max_entries"] = DefaultMaxEntries
+	}
+	return nil
+}
+
+// migrate upgrades data from its on-disk version to CacheVersion, in memory
+// only. It returns data unchanged when it is current, and the version it was
+// upgraded from otherwise. The file itself is only rewritten, after a backup,
+// by the next Save, which callers run under the cache lock.
+func migrate(cacheFile string, data []byte) ([]byte, string, error) {
+	var header struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+
+	from := header.Version
+	if from == "" {
+		// Files from before versioning match the first format
+		from = "1.0"
+	}
+	if from == CacheVersion {
+		return data, "", nil
+	}
+	newer, err := versionNewer(from, CacheVersion)
+	if err != nil {
+		return nil, "", err
+	}
+	if newer {
+		return nil, "", fmt.Errorf("%w (cache format %s, this binary supports up to %s); upgrade gh-notify or remove %s",
+			ErrNewerVersion, from, CacheVersion, cacheFile)
+	}
+
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, "", fmt.Errorf("%w: %v", ErrCorrupt, err)
+	}
+
+	for version := from; version != CacheVersion; {
+		step, ok := migrations[version]
+		if !ok {
+			return nil, "", fmt.Errorf("no migration from cache format %s to %s", version, CacheVersion)
+		}
+		if err := step.migrate(doc); err != nil {
+			return nil, "", fmt.Errorf("failed to migrate cache from format %s to %s: %w", version, step.to, err)
+		}
+		version = step.to
+		doc["version"] = version
+	}
+
+	migrated, err := json.Marshal(doc)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to encode migrated cache: %w", err)
+	}
+
+	return migrated, from, nil
+}
+
+// backupCacheFile keeps the cache file written in version from at cacheFile
+// plus ".v<version>.bak", before Save overwrites it with the migrated cache.
+// The first backup of each version is kept.
+func backupCacheFile(cacheFile, from string) error {
+	backup := fmt.Sprintf("%s.v%s.bak", cacheFile, from)
+	if _, err := os.Stat(backup); !os.IsNotExist(err) {
+		return nil
+	}
+
+	data, err := os.ReadFile(cacheFile)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache file: %w", err)
+	}
+	if err := writeFileAtomic(backup, data); err != nil {
+		return fmt.Errorf("failed to back up cache before migration: %w", err)
+	}
+	return nil
+}
+
+// versionNewer reports whether version a is newer than version b, comparing
+// dot-separated numbers such as "1.10" and "1.2"
+func versionNewer(a, b string) (bool, error) {
+	partsA, partsB := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var numA, numB int
+		var err error
+		if i < len(partsA) {
+			if numA, err = strconv.Atoi(partsA[i]); err != nil {
+				return false, fmt.Errorf("invalid cache version %q", a)
+			}
+		}
+		if i < len(partsB) {
+			if numB, err = strconv.Atoi(partsB[i]); err != nil {
+				return false, fmt.Errorf("invalid cache version %q", b)
+			}
+		}
+		if numA != numB {
+			return numA > numB, nil
+		}
+	}
+	return false, nil
+}
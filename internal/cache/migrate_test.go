@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestLoad_MigratesOldVersion tests that a 1.0 cache is upgraded in memory on
+// load, and backed up only when saved
+func TestLoad_MigratesOldVersion(t *testing.T) {
+	dir := t.TempDir()
+	cacheFile := filepath.Join(dir, "notifications.json")
+	original := `{"version": "1.0", "notifications": null, "stars": null, "max_entries": 0, "muted": ["7"]}`
+	if err := os.WriteFile(cacheFile, []byte(original), 0644); err != nil {
+		t.Fatalf("Failed to write cache file: %v", err)
+	}
+
+	c := New(dir)
+	if err := c.Load(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if c.Version != CacheVersion {
+		t.Errorf("Expected version %s, got %s", CacheVersion, c.Version)
+	}
+	if c.Notifications == nil || c.Stars == nil || c.MaxEntries != DefaultMaxEntries {
+		t.Errorf("Expected empty lists and default max entries, got %+v", c)
+	}
+	if !c.IsMuted("7") {
+		t.Errorf("Expected other fields to survive the migration, got %v", c.Muted)
+	}
+
+	// Loading alone writes nothing, as read-only commands load without the lock
+	if data, _ := os.ReadFile(cacheFile); string(data) != original {
+		t.Errorf("Expected the cache file to be left alone on load, got %q", data)
+	}
+	if _, err := os.Stat(cacheFile + ".v1.0.bak"); !os.IsNotExist(err) {
+		t.Errorf("Expected no backup before the first save, got %v", err)
+	}
+
+	if err := c.Save(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	backup, err := os.ReadFile(cacheFile + ".v1.0.bak")
+	if err != nil || string(backup) != original {
+		t.Errorf("Expected the original file as backup, got %q (%v)", backup, err)
+	}
+	saved, _ := os.ReadFile(cacheFile)
+	if !strings.Contains(string(saved), `"version": "`+CacheVersion+`"`) {
+		t.Errorf("Expected the saved cache to carry version %s", CacheVersion)
+	}
+
+	t.Logf("✓ Cache migration test passed!")
+}
+
+// TestLoad_UnversionedFile tests that files without a version are treated as 1.0
+func TestLoad_UnversionedFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "notifications.json"), []byte(`{"notifications": [{"id": "1"}]}`), 0644); err != nil {
+		t.Fatalf("Failed to write cache file: %v", err)
+	}
+
+	c := New(dir)
+	if err := c.Load(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if c.Version != CacheVersion || len(c.Notifications) != 1 {
+		t.Errorf("Expected a migrated cache with one notification, got %+v", c)
+	}
+	if err := c.Save(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notifications.json.v1.0.bak")); err != nil {
+		t.Errorf("Expected a 1.0 backup: %v", err)
+	}
+
+	t.Logf("✓ Unversioned cache test passed!")
+}
+
+// TestLoad_NewerVersionRefused tests that caches from a newer binary are refused and left alone
+func TestLoad_NewerVersionRefused(t *testing.T) {
+	dir := t.TempDir()
+	cacheFile := filepath.Join(dir, "notifications.json")
+	if err := os.WriteFile(cacheFile, []byte(`{"version": "9.0", "notifications": {"new": "shape"}}`), 0644); err != nil {
+		t.Fatalf("Failed to write cache file: %v", err)
+	}
+
+	err := New(dir).Load(dir)
+	if !errors.Is(err, ErrNewerVersion) || errors.Is(err, ErrCorrupt) {
+		t.Fatalf("Expected ErrNewerVersion, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "cache format 9.0, this binary supports up to "+CacheVersion) {
+		t.Errorf("Expected both versions in the message, got %q", err)
+	}
+
+	if _, err := os.Stat(cacheFile); err != nil {
+		t.Errorf("Expected the newer cache to stay in place: %v", err)
+	}
+
+	t.Logf("✓ Newer cache refusal test passed!")
+}
+
+// TestVersionNewer tests version comparison
+func TestVersionNewer(t *testing.T) {
+	tests := []struct {
+		a, b     string
+		expected bool
+	}{
+		{"1.1", "1.0", true},
+		{"1.0", "1.1", false},
+		{"1.10", "1.2", true},
+		{"2", "1.9", true},
+		{"1.1", "1.1", false},
+		{"1.1.1", "1.1", true},
+	}
+
+	for _, tt := range tests {
+		got, err := versionNewer(tt.a, tt.b)
+		if err != nil || got != tt.expected {
+			t.Errorf("versionNewer(%q, %q) = %v, %v; want %v", tt.a, tt.b, got, err, tt.expected)
+		}
+	}
+
+	if _, err := versionNewer("beta", "1.1"); err == nil {
+		t.Errorf("Expected an error for a non-numeric version")
+	}
+
+	t.Logf("✓ Version comparison test passed!")
+}
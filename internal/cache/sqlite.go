@@ -0,0 +1,514 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/logger"
+
+	_ "modernc.org/sqlite" // Pure Go driver, registered as "sqlite"
+)
+
+// sqliteSchemaVersion is stored in PRAGMA user_version. Bump it with a step
+// in sqliteMigrations when the schema changes.
+const sqliteSchemaVersion = 2
+
+// sqliteSchema keeps each notification, star and history record in a row of
+// its own, as JSON next to the columns queries filter and sort on. The state
+// table holds the rest of the cache as one JSON document.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS state (
+	id   INTEGER PRIMARY KEY CHECK (id = 1),
+	data TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS notifications (
+	id         TEXT PRIMARY KEY,
+	repository TEXT NOT NULL COLLATE NOCASE,
+	reason     TEXT NOT NULL,
+	updated_at INTEGER NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS notifications_repository ON notifications (repository, updated_at);
+CREATE INDEX IF NOT EXISTS notifications_reason ON notifications (reason, updated_at);
+CREATE INDEX IF NOT EXISTS notifications_updated_at ON notifications (updated_at);
+CREATE TABLE IF NOT EXISTS stars (
+	id         TEXT PRIMARY KEY,
+	starred_at INTEGER NOT NULL,
+	data       TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS stars_starred_at ON stars (starred_at);
+CREATE TABLE IF NOT EXISTS history (
+	id            TEXT PRIMARY KEY,
+	repository    TEXT NOT NULL COLLATE NOCASE,
+	reason        TEXT NOT NULL,
+	last_activity INTEGER NOT NULL,
+	data          TEXT NOT NULL
+);
+CREATE INDEX IF NOT EXISTS history_repository ON history (repository, last_activity);
+CREATE INDEX IF NOT EXISTS history_reason ON history (reason, last_activity);
+CREATE INDEX IF NOT EXISTS history_last_activity ON history (last_activity);
+`
+
+// sqliteMigrations holds the steps from each schema version to the next;
+// step i upgrades a database at version i
+var sqliteMigrations = []string{
+	sqliteSchema,
+	// saved_at tells whether the JSON cache was saved after the database.
+	// Databases from before it count as saved now.
+	`ALTER TABLE state ADD COLUMN saved_at INTEGER NOT NULL DEFAULT 0;
+	UPDATE state SET saved_at = CAST(strftime('%s', 'now') AS INTEGER) * 1000000000;`,
+}
+
+// sqliteStore keeps the cache in notifications.db. Saves only write the rows
+// that changed, and queries filter on indexed columns instead of reading
+// everything.
+type sqliteStore struct {
+	db  *sql.DB
+	dir string
+	// imported is false until the first save, and again when the JSON
+	// cache was saved after the database, as after switching to json and
+	// back. Until the next save the store reads the JSON cache, so switching
+	// backends keeps existing data.
+	imported bool
+}
+
+func openSQLiteStore(cacheDir string) (*sqliteStore, error) {
+	if err := os.MkdirAll(cacheDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create cache directory: %w", err)
+	}
+
+	// busy_timeout makes readers wait out a concurrent save instead of failing,
+	// and immediate transactions take the write lock before reading
+	dsn := "file:" + filepath.Join(cacheDir, "notifications.db") +
+		"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_txlock=immediate"
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open cache database: %w", err)
+	}
+	// One connection keeps transactions and pragmas on the same handle
+	db.SetMaxOpenConns(1)
+
+	s := &sqliteStore{db: db, dir: cacheDir}
+	if err := s.init(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// init brings the schema up to date, refusing databases from a newer
+// gh-notify, and decides whether the JSON cache still needs importing
+func (s *sqliteStore) init() error {
+	version, err := sqliteVersion(s.db)
+	if err != nil {
+		return err
+	}
+	if version < sqliteSchemaVersion {
+		if err := s.migrate(); err != nil {
+			return err
+		}
+	}
+
+	var savedAt int64
+	err = s.db.QueryRow(`SELECT saved_at FROM state WHERE id = 1`).Scan(&savedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache state: %w", err)
+	}
+	s.imported = true
+
+	info, err := os.Stat(filepath.Join(s.dir, "notifications.json"))
+	if err == nil && info.ModTime().UnixNano() > savedAt {
+		logger.Info().Str("cache_dir", s.dir).Msg("JSON cache is newer than the SQLite cache, importing it again")
+		s.imported = false
+	}
+
+	return nil
+}
+
+// migrate runs the pending migration steps in one transaction, so concurrent
+// opens of an old database upgrade it once
+func (s *sqliteStore) migrate() (err error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin cache database migration: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	// Another process may have migrated since the version was first read
+	version, err := sqliteVersion(tx)
+	if err != nil {
+		return err
+	}
+	for ; version < sqliteSchemaVersion; version++ {
+		if _, err = tx.Exec(sqliteMigrations[version]); err != nil {
+			return fmt.Errorf("failed to migrate cache database to schema %d: %w", version+1, err)
+		}
+	}
+	if _, err = tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, sqliteSchemaVersion)); err != nil {
+		return fmt.Errorf("failed to set cache database version: %w", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cache database migration: %w", err)
+	}
+	return nil
+}
+
+// sqliteVersion reads the schema version, refusing databases from a newer
+// gh-notify
+func sqliteVersion(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}) (int, error) {
+	var version int
+	if err := q.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to read cache database version: %w", err)
+	}
+	if version > sqliteSchemaVersion {
+		return 0, fmt.Errorf("%w: database schema %d, this binary supports up to %d", ErrNewerVersion, version, sqliteSchemaVersion)
+	}
+	return version, nil
+}
+
+func (s *sqliteStore) Load(c *Cache) error {
+	if !s.imported {
+		// The first save writes the JSON cache into the database
+		err := c.Load(s.dir)
+		c.historyStore().maxRecords = 0
+		if err != nil {
+			return err
+		}
+		if len(c.Notifications) > 0 || len(c.historyStore().Records) > 0 {
+			logger.Info().Str("cache_dir", s.dir).Msg("Importing JSON cache into SQLite")
+		}
+		return nil
+	}
+
+	var data string
+	if err := s.db.QueryRow(`SELECT data FROM state WHERE id = 1`).Scan(&data); err != nil {
+		return fmt.Errorf("failed to read cache state: %w", err)
+	}
+	if err := json.Unmarshal([]byte(data), c); err != nil {
+		return fmt.Errorf("failed to unmarshal cache state: %w", err)
+	}
+	c.Version = CacheVersion
+	if c.MaxEntries == 0 {
+		c.MaxEntries = DefaultMaxEntries
+	}
+
+	var err error
+	if c.Notifications, err = s.Notifications(Query{}); err != nil {
+		return err
+	}
+	if c.Stars, err = s.Stars(time.Time{}); err != nil {
+		return err
+	}
+
+	history := newHistory()
+	history.maxRecords = 0
+	if history.Records, err = s.History(Query{}); err != nil {
+		return err
+	}
+	c.history = history
+
+	return nil
+}
+
+// sqliteRow is a row to write: its ID, the indexed columns in table order,
+// and the JSON document
+type sqliteRow struct {
+	id      string
+	columns []interface{}
+	data    []byte
+}
+
+func (s *sqliteStore) Save(c *Cache) (err error) {
+	// Rows are queried rather than loaded whole, so only age limits apply
+	c.cleanup(0)
+	history := c.historyStore()
+	history.prune(time.Now().UTC())
+
+	state := *c
+	state.Notifications, state.Stars = nil, nil
+	stateData, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cache state: %w", err)
+	}
+
+	var notifications, stars, records []sqliteRow
+	for _, entry := range c.Notifications {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return fmt.Errorf("failed to marshal notification %s: %w", entry.ID, err)
+		}
+		notifications = append(notifications, sqliteRow{entry.ID, []interface{}{entry.Repository, entry.Reason, sqliteTime(entry.UpdatedAt)}, data})
+	}
+	for _, star := range c.Stars {
+		data, err := json.Marshal(star)
+		if err != nil {
+			return fmt.Errorf("failed to marshal star event %s: %w", star.ID, err)
+		}
+		stars = append(stars, sqliteRow{star.ID, []interface{}{sqliteTime(star.StarredAt)}, data})
+	}
+	for _, record := range history.Records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("failed to marshal history record %s: %w", record.ID, err)
+		}
+		records = append(records, sqliteRow{record.ID, []interface{}{record.Repository, record.Reason, sqliteTime(record.LastActivity())}, data})
+	}
+
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin cache transaction: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			_ = tx.Rollback()
+		}
+	}()
+
+	result, err := tx.Exec(`INSERT INTO state (id, data) VALUES (1, ?)
+		ON CONFLICT (id) DO UPDATE SET data = excluded.data WHERE data != excluded.data`, string(stateData))
+	if err != nil {
+		return fmt.Errorf("failed to write cache state: %w", err)
+	}
+	changed, _ := result.RowsAffected()
+	for _, table := range []struct {
+		name    string
+		columns []string
+		rows    []sqliteRow
+	}{
+		{"notifications", []string{"repository", "reason", "updated_at"}, notifications},
+		{"stars", []string{"starred_at"}, stars},
+		{"history", []string{"repository", "reason", "last_activity"}, records},
+	} {
+		n, syncErr := syncRows(tx, table.name, table.columns, table.rows)
+		if syncErr != nil {
+			return syncErr
+		}
+		changed += int64(n)
+	}
+
+	// Unchanged saves leave saved_at alone so they write nothing
+	if changed > 0 || !s.imported {
+		if _, err = tx.Exec(`UPDATE state SET saved_at = ? WHERE id = 1`, time.Now().UnixNano()); err != nil {
+			return fmt.Errorf("failed to write cache state: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cache transaction: %w", err)
+	}
+	s.imported = true
+
+	return nil
+}
+
+// syncRows makes table hold exactly rows and returns how many rows it wrote
+// or deleted. Rows whose JSON is unchanged are left alone, so a save only
+// writes what changed since the last one.
+func syncRows(tx *sql.Tx, table string, columns []string, rows []sqliteRow) (int, error) {
+	existing := make(map[string]bool)
+	ids, err := tx.Query(`SELECT id FROM ` + table)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+	for ids.Next() {
+		var id string
+		if err := ids.Scan(&id); err != nil {
+			_ = ids.Close()
+			return 0, fmt.Errorf("failed to read %s: %w", table, err)
+		}
+		existing[id] = true
+	}
+	if err := ids.Err(); err != nil {
+		return 0, fmt.Errorf("failed to read %s: %w", table, err)
+	}
+
+	placeholders := strings.Repeat("?, ", len(columns)+1) + "?"
+	updates := make([]string, 0, len(columns)+1)
+	for _, column := range append(columns, "data") {
+		updates = append(updates, column+" = excluded."+column)
+	}
+	upsert, err := tx.Prepare(fmt.Sprintf(`INSERT INTO %s (id, %s, data) VALUES (%s)
+		ON CONFLICT (id) DO UPDATE SET %s WHERE data != excluded.data`,
+		table, strings.Join(columns, ", "), placeholders, strings.Join(updates, ", ")))
+	if err != nil {
+		return 0, fmt.Errorf("failed to prepare %s update: %w", table, err)
+	}
+	defer func() { _ = upsert.Close() }()
+
+	written := 0
+	for _, row := range rows {
+		args := append([]interface{}{row.id}, row.columns...)
+		result, err := upsert.Exec(append(args, string(row.data))...)
+		if err != nil {
+			return 0, fmt.Errorf("failed to write %s %s: %w", table, row.id, err)
+		}
+		if n, _ := result.RowsAffected(); n > 0 {
+			written++
+		}
+		delete(existing, row.id)
+	}
+
+	for id := range existing {
+		if _, err := tx.Exec(`DELETE FROM `+table+` WHERE id = ?`, id); err != nil {
+			return 0, fmt.Errorf("failed to delete %s %s: %w", table, id, err)
+		}
+	}
+
+	logger.Debug().Str("table", table).Int("written", written).Int("deleted", len(existing)).Msg("Cache rows saved")
+
+	return written + len(existing), nil
+}
+
+func (s *sqliteStore) Notifications(q Query) ([]CacheEntry, error) {
+	if !s.imported {
+		return (&jsonStore{dir: s.dir}).Notifications(q)
+	}
+
+	where, args := q.sqlWhere("updated_at")
+	rows, err := s.db.Query(`SELECT data FROM notifications`+where+` ORDER BY updated_at DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query notifications: %w", err)
+	}
+
+	var result []CacheEntry
+	err = scanRows(rows, func(data []byte) error {
+		var entry CacheEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return err
+		}
+		result = append(result, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read notifications: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *sqliteStore) Stars(since time.Time) ([]StarEvent, error) {
+	if !s.imported {
+		return (&jsonStore{dir: s.dir}).Stars(since)
+	}
+
+	rows, err := s.db.Query(`SELECT data FROM stars WHERE starred_at >= ? ORDER BY starred_at DESC`, sqliteTime(since))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query star events: %w", err)
+	}
+
+	var result []StarEvent
+	err = scanRows(rows, func(data []byte) error {
+		var star StarEvent
+		if err := json.Unmarshal(data, &star); err != nil {
+			return err
+		}
+		result = append(result, star)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read star events: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *sqliteStore) History(q Query) ([]HistoryRecord, error) {
+	if !s.imported {
+		return (&jsonStore{dir: s.dir}).History(q)
+	}
+
+	where, args := q.sqlWhere("last_activity")
+	rows, err := s.db.Query(`SELECT data FROM history`+where+` ORDER BY last_activity DESC`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query history: %w", err)
+	}
+
+	result := []HistoryRecord{}
+	err = scanRows(rows, func(data []byte) error {
+		var record HistoryRecord
+		if err := json.Unmarshal(data, &record); err != nil {
+			return err
+		}
+		result = append(result, record)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read history: %w", err)
+	}
+
+	return result, nil
+}
+
+func (s *sqliteStore) Close() error {
+	return s.db.Close()
+}
+
+// sqlWhere turns the query into a WHERE clause, with timeColumn compared
+// against Since
+func (q Query) sqlWhere(timeColumn string) (string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	if q.Repository != "" {
+		// LIKE is case-insensitive for ASCII, like list's repository filter
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(q.Repository)
+		conditions = append(conditions, `repository LIKE ? ESCAPE '\'`)
+		args = append(args, "%"+escaped+"%")
+	}
+	if q.Reason != "" {
+		conditions = append(conditions, "reason = ?")
+		args = append(args, q.Reason)
+	}
+	if !q.Since.IsZero() {
+		conditions = append(conditions, timeColumn+" >= ?")
+		args = append(args, sqliteTime(q.Since))
+	}
+
+	if len(conditions) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// scanRows passes the JSON document of each row to decode and closes rows
+func scanRows(rows *sql.Rows, decode func(data []byte) error) error {
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+		if err := decode(data); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+// sqliteTime is the indexed form of a time, in nanoseconds since the epoch.
+// The zero time sorts first.
+func sqliteTime(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Storage backends selectable with storage.backend
+const (
+	BackendJSON   = "json"
+	BackendSQLite = "sqlite"
+)
+
+// Store persists caches. Commands load a whole cache to update it, while
+// list, open, history and the waybar output only query what they show.
+type Store interface {
+	// Load reads the cache, including its history, into c
+	Load(c *Cache) error
+	// Save cleans up c and writes it
+	Save(c *Cache) error
+	// Notifications returns the cached notifications matching q, newest first
+	Notifications(q Query) ([]CacheEntry, error)
+	// Stars returns the cached star events starred at or after since, newest first
+	Stars(since time.Time) ([]StarEvent, error)
+	// History returns the history records matching q, newest activity first
+	History(q Query) ([]HistoryRecord, error)
+	Close() error
+}
+
+// Query selects notifications or history records. Zero fields match everything.
+type Query struct {
+	Repository string    // Case-insensitive part of the repository name
+	Reason     string    // Exact reason
+	Since      time.Time // Updated (history: last active) at or after this time
+}
+
+func (q Query) matches(repository, reason string, at time.Time) bool {
+	if q.Repository != "" && !strings.Contains(strings.ToLower(repository), strings.ToLower(q.Repository)) {
+		return false
+	}
+	if q.Reason != "" && reason != q.Reason {
+		return false
+	}
+	return q.Since.IsZero() || !at.Before(q.Since)
+}
+
+// OpenStore opens the cache storage in cacheDir. An empty backend is JSON.
+func OpenStore(backend, cacheDir string) (Store, error) {
+	switch backend {
+	case "", BackendJSON:
+		return &jsonStore{dir: cacheDir}, nil
+	case BackendSQLite:
+		return openSQLiteStore(cacheDir)
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// jsonStore keeps the cache in notifications.json and history.json, which
+// are rewritten whole on every save. Queries read the files and filter in
+// memory.
+type jsonStore struct {
+	dir string
+}
+
+func (s *jsonStore) Load(c *Cache) error {
+	return c.Load(s.dir)
+}
+
+func (s *jsonStore) Save(c *Cache) error {
+	return c.Save(s.dir)
+}
+
+// read loads the cache files. A corrupt cache comes back empty together with
+// the ErrCorrupt error from Load.
+func (s *jsonStore) read() (*Cache, error) {
+	c := New(s.dir)
+	err := c.Load(s.dir)
+	return c, err
+}
+
+func (s *jsonStore) Notifications(q Query) ([]CacheEntry, error) {
+	c, err := s.read()
+
+	var result []CacheEntry
+	for _, entry := range c.Notifications {
+		if q.matches(entry.Repository, entry.Reason, entry.UpdatedAt) {
+			result = append(result, entry)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].UpdatedAt.After(result[j].UpdatedAt)
+	})
+
+	return result, err
+}
+
+func (s *jsonStore) Stars(since time.Time) ([]StarEvent, error) {
+	c, err := s.read()
+
+	var result []StarEvent
+	for _, star := range c.Stars {
+		if !star.StarredAt.Before(since) {
+			result = append(result, star)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].StarredAt.After(result[j].StarredAt)
+	})
+
+	return result, err
+}
+
+func (s *jsonStore) History(q Query) ([]HistoryRecord, error) {
+	c, err := s.read()
+
+	var result []HistoryRecord
+	for _, record := range c.History() {
+		if q.matches(record.Repository, record.Reason, record.LastActivity()) {
+			result = append(result, record)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].LastActivity().After(result[j].LastActivity())
+	})
+
+	return result, err
+}
+
+func (s *jsonStore) Close() error {
+	return nil
+}
@@ -0,0 +1,331 @@
+package cache
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// storeFixture returns a cache with notifications, stars and history spread
+// over repositories, reasons and times
+func storeFixture(now time.Time) *Cache {
+	c := New("")
+	c.AddNotifications([]CacheEntry{
+		{ID: "1", Repository: "owner/repo", Reason: "mention", Timestamp: now, UpdatedAt: now.Add(-3 * time.Hour)},
+		{ID: "2", Repository: "Owner/Repo", Reason: "review_requested", Timestamp: now, UpdatedAt: now.Add(-time.Hour)},
+		{ID: "3", Repository: "someone/tool", Reason: "mention", Timestamp: now, UpdatedAt: now.Add(-48 * time.Hour)},
+		{ID: "4", Repository: "owner/repo_100%", Reason: "assign", Timestamp: now, UpdatedAt: now.Add(-2 * time.Hour)},
+	})
+	c.RemoveNotifications("3")
+	c.AddStarEvents([]StarEvent{
+		{ID: "s1", Repository: "owner/repo", StarredAt: now.Add(-2 * time.Hour)},
+		{ID: "s2", Repository: "owner/repo", StarredAt: now.Add(-10 * time.Minute)},
+	})
+	c.Mute("2")
+	c.LastModified = "Mon, 01 Jan 2024 00:00:00 GMT"
+	return c
+}
+
+// notificationIDs returns the IDs of entries in order
+func notificationIDs(entries []CacheEntry) []string {
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+// TestStores_Query tests filtered queries and reloads against both backends
+func TestStores_Query(t *testing.T) {
+	now := time.Now().UTC()
+
+	for _, backend := range []string{BackendJSON, BackendSQLite} {
+		t.Run(backend, func(t *testing.T) {
+			dir := t.TempDir()
+
+			store, err := OpenStore(backend, dir)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := store.Save(storeFixture(now)); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if err := store.Close(); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+
+			store, err = OpenStore(backend, dir)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			t.Cleanup(func() { _ = store.Close() })
+
+			queries := []struct {
+				name     string
+				query    Query
+				expected []string
+			}{
+				{"all, newest first", Query{}, []string{"2", "4", "1"}},
+				{"partial repository, any case", Query{Repository: "OWNER/"}, []string{"2", "4", "1"}},
+				{"wildcards are literal", Query{Repository: "repo_100%"}, []string{"4"}},
+				{"reason", Query{Reason: "mention"}, []string{"1"}},
+				{"since", Query{Since: now.Add(-2 * time.Hour)}, []string{"2", "4"}},
+				{"combined", Query{Repository: "repo", Reason: "review_requested", Since: now.Add(-2 * time.Hour)}, []string{"2"}},
+			}
+			for _, tt := range queries {
+				entries, err := store.Notifications(tt.query)
+				if err != nil {
+					t.Fatalf("%s: expected no error, got %v", tt.name, err)
+				}
+				if got := notificationIDs(entries); strings.Join(got, ",") != strings.Join(tt.expected, ",") {
+					t.Errorf("%s: expected %v, got %v", tt.name, tt.expected, got)
+				}
+			}
+
+			stars, err := store.Stars(now.Add(-time.Hour))
+			if err != nil || len(stars) != 1 || stars[0].ID != "s2" {
+				t.Errorf("Expected only the recent star, got %+v (%v)", stars, err)
+			}
+
+			records, err := store.History(Query{Repository: "tool"})
+			if err != nil || len(records) != 1 || records[0].ID != "3" || records[0].Status() != "read" {
+				t.Errorf("Expected the read thread in the history, got %+v (%v)", records, err)
+			}
+
+			// A full load restores the whole cache
+			c := New(dir)
+			if err := store.Load(c); err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if len(c.Notifications) != 3 || len(c.Stars) != 2 || len(c.History()) != 4 {
+				t.Errorf("Expected 3 notifications, 2 stars and 4 history records, got %d, %d and %d", len(c.Notifications), len(c.Stars), len(c.History()))
+			}
+			if !c.IsMuted("2") || c.LastModified == "" || c.Version != CacheVersion {
+				t.Errorf("Expected the cache state to survive, got %+v", c)
+			}
+		})
+	}
+
+	if _, err := OpenStore("postgres", t.TempDir()); err == nil {
+		t.Error("Expected an error for an unknown backend")
+	}
+
+	t.Logf("✓ Store query test passed!")
+}
+
+// TestSQLiteStore_IncrementalSave tests that saves only write changed rows
+func TestSQLiteStore_IncrementalSave(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+
+	store, err := openSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	c := storeFixture(now)
+	if err := store.Save(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	changes := func() int {
+		var n int
+		if err := store.db.QueryRow(`SELECT total_changes()`).Scan(&n); err != nil {
+			t.Fatalf("Failed to read changes: %v", err)
+		}
+		return n
+	}
+
+	before := changes()
+	if err := store.Save(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if after := changes(); after != before {
+		t.Errorf("Expected an unchanged save to write nothing, got %d changes", after-before)
+	}
+
+	// One notification read: its row goes, its history record changes and
+	// the state records the save time
+	c.RemoveNotifications("1")
+	before = changes()
+	if err := store.Save(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if after := changes(); after-before != 3 {
+		t.Errorf("Expected 3 changed rows, got %d", after-before)
+	}
+
+	entries, err := store.Notifications(Query{})
+	if err != nil || len(entries) != 2 {
+		t.Errorf("Expected 2 notifications left, got %v (%v)", notificationIDs(entries), err)
+	}
+
+	t.Logf("✓ Incremental save test passed!")
+}
+
+// TestSQLiteStore_NoEntryCap tests that SQLite keeps more than MaxEntries notifications
+func TestSQLiteStore_NoEntryCap(t *testing.T) {
+	store, err := openSQLiteStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	now := time.Now().UTC()
+	c := New("")
+	c.MaxEntries = 2
+	c.AddNotifications([]CacheEntry{
+		{ID: "1", Timestamp: now, UpdatedAt: now},
+		{ID: "2", Timestamp: now, UpdatedAt: now},
+		{ID: "3", Timestamp: now, UpdatedAt: now},
+	})
+	if err := store.Save(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries, err := store.Notifications(Query{})
+	if err != nil || len(entries) != 3 {
+		t.Errorf("Expected all 3 notifications, got %v (%v)", notificationIDs(entries), err)
+	}
+
+	t.Logf("✓ SQLite entry cap test passed!")
+}
+
+// TestSQLiteStore_ImportsJSON tests that switching to SQLite keeps the JSON cache
+func TestSQLiteStore_ImportsJSON(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+
+	if err := storeFixture(now).Save(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	store, err := openSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// Before the first save, queries read the JSON cache
+	entries, err := store.Notifications(Query{Reason: "mention"})
+	if err != nil || len(entries) != 1 {
+		t.Errorf("Expected the JSON notification, got %v (%v)", notificationIDs(entries), err)
+	}
+
+	c := New(dir)
+	if err := store.Load(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.Save(c); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_ = store.Close()
+
+	// Once imported, the database no longer depends on the JSON files
+	if err := os.Remove(filepath.Join(dir, "notifications.json")); err != nil {
+		t.Fatalf("Failed to remove JSON cache: %v", err)
+	}
+
+	store, err = openSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	entries, err = store.Notifications(Query{})
+	if err != nil || len(entries) != 3 {
+		t.Errorf("Expected 3 imported notifications, got %v (%v)", notificationIDs(entries), err)
+	}
+	records, err := store.History(Query{})
+	if err != nil || len(records) != 4 {
+		t.Errorf("Expected 4 imported history records, got %d (%v)", len(records), err)
+	}
+
+	t.Logf("✓ JSON import test passed!")
+}
+
+// TestSQLiteStore_ReimportsNewerJSON tests that a JSON cache saved after the
+// database, as after switching to json and back, is imported again
+func TestSQLiteStore_ReimportsNewerJSON(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+
+	store, err := openSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := store.Save(storeFixture(now)); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	_ = store.Close()
+
+	// The JSON backend reads a thread afterwards
+	c := storeFixture(now)
+	c.RemoveNotifications("1")
+	if err := c.Save(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	// Filesystem timestamps are coarser than saved_at, so make the order explicit
+	later := time.Now().Add(time.Second)
+	if err := os.Chtimes(filepath.Join(dir, "notifications.json"), later, later); err != nil {
+		t.Fatalf("Failed to touch the JSON cache: %v", err)
+	}
+
+	store, err = openSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	entries, err := store.Notifications(Query{})
+	if err != nil || strings.Join(notificationIDs(entries), ",") != "2,4" {
+		t.Errorf("Expected the newer JSON notifications, got %v (%v)", notificationIDs(entries), err)
+	}
+
+	t.Logf("✓ JSON re-import test passed!")
+}
+
+// TestSQLiteStore_MigratesSchema tests upgrading a database from schema 1
+// without re-importing an older JSON cache
+func TestSQLiteStore_MigratesSchema(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UTC()
+
+	if err := storeFixture(now).Save(dir); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	old := now.Add(-time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "notifications.json"), old, old); err != nil {
+		t.Fatalf("Failed to age JSON cache: %v", err)
+	}
+
+	db, err := sql.Open("sqlite", "file:"+filepath.Join(dir, "notifications.db"))
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	for _, stmt := range []string{sqliteMigrations[0], `PRAGMA user_version = 1`, `INSERT INTO state (id, data) VALUES (1, '{}')`} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatalf("Failed to create schema 1 database: %v", err)
+		}
+	}
+	_ = db.Close()
+
+	store, err := openSQLiteStore(dir)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	t.Cleanup(func() { _ = store.Close() })
+
+	version, err := sqliteVersion(store.db)
+	if err != nil || version != sqliteSchemaVersion {
+		t.Errorf("Expected schema %d, got %d (%v)", sqliteSchemaVersion, version, err)
+	}
+	if !store.imported {
+		t.Error("Expected the migrated database to be used instead of the older JSON cache")
+	}
+
+	t.Logf("✓ SQLite schema migration test passed!")
+}
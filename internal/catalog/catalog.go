@@ -0,0 +1,137 @@
+// Package catalog describes every GitHub notification reason and subject type
+// once, so desktop notifications, list and the waybar tooltip present them
+// the same way.
+package catalog
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/bnema/gh-notify/internal/nerdfonts"
+)
+
+// Entry describes how a reason or subject type is presented
+type Entry struct {
+	Label    string // Human readable text, e.g. "Review requested"
+	Icon     string // Nerd Font symbol; empty for reasons that use the subject type icon
+	Urgency  string // Default notify-send urgency: low, normal or critical
+	Priority int    // Sort order, lower sorts first
+}
+
+// Urgency levels in increasing order
+var urgencyRank = map[string]int{"low": 1, "normal": 2, "critical": 3}
+
+// reasons lists every notification reason GitHub documents
+// (https://docs.github.com/en/rest/activity/notifications#about-notification-reasons)
+var reasons = map[string]Entry{
+	"security_alert":           {Label: "Security alert", Icon: nerdfonts.Security, Urgency: "critical", Priority: 0},
+	"review_requested":         {Label: "Review requested", Icon: nerdfonts.ReviewRequested, Urgency: "normal", Priority: 1},
+	"approval_requested":       {Label: "Approval requested", Icon: nerdfonts.Approval, Urgency: "normal", Priority: 1},
+	"assign":                   {Label: "Assigned", Icon: nerdfonts.Assign, Urgency: "normal", Priority: 2},
+	"mention":                  {Label: "Mentioned", Icon: nerdfonts.Mention, Urgency: "normal", Priority: 3},
+	"team_mention":             {Label: "Team mentioned", Icon: nerdfonts.TeamMention, Urgency: "normal", Priority: 4},
+	"invitation":               {Label: "Invitation", Icon: nerdfonts.Invitation, Urgency: "normal", Priority: 5},
+	"author":                   {Label: "Author update", Icon: nerdfonts.Author, Urgency: "normal", Priority: 6},
+	"comment":                  {Label: "New comment", Urgency: "normal", Priority: 7},
+	"state_change":             {Label: "State changed", Icon: nerdfonts.StateChange, Urgency: "normal", Priority: 8},
+	"security_advisory_credit": {Label: "Security advisory credit", Icon: nerdfonts.Credit, Urgency: "normal", Priority: 9},
+	"ci_activity":              {Label: "CI activity", Urgency: "normal", Priority: 10},
+	"member_feature_requested": {Label: "Feature requested", Icon: nerdfonts.FeatureRequest, Urgency: "normal", Priority: 11},
+	"manual":                   {Label: "Manual subscription", Urgency: "normal", Priority: 12},
+	"subscribed":               {Label: "Subscribed", Urgency: "normal", Priority: 13},
+}
+
+// types lists every notification subject type GitHub sends
+var types = map[string]Entry{
+	"RepositoryVulnerabilityAlert":     {Label: "Vulnerability alert", Icon: nerdfonts.Security, Urgency: "critical", Priority: 0},
+	"RepositoryDependabotAlertsThread": {Label: "Dependabot alerts", Icon: nerdfonts.Security, Urgency: "normal", Priority: 1},
+	"RepositoryAdvisory":               {Label: "Security advisory", Icon: nerdfonts.Security, Urgency: "normal", Priority: 1},
+	"PullRequest":                      {Label: "Pull request", Icon: nerdfonts.PullRequest, Urgency: "normal", Priority: 2},
+	"Issue":                            {Label: "Issue", Icon: nerdfonts.Issue, Urgency: "normal", Priority: 3},
+	"Discussion":                       {Label: "Discussion", Icon: nerdfonts.Discussion, Urgency: "normal", Priority: 4},
+	"RepositoryInvitation":             {Label: "Repository invitation", Icon: nerdfonts.Invitation, Urgency: "normal", Priority: 5},
+	"Commit":                           {Label: "Commit", Icon: nerdfonts.Commit, Urgency: "normal", Priority: 6},
+	"Release":                          {Label: "Release", Icon: nerdfonts.Release, Urgency: "normal", Priority: 7},
+	"CheckSuite":                       {Label: "Check suite", Icon: nerdfonts.CheckSuite, Urgency: "normal", Priority: 8},
+	"WorkflowRun":                      {Label: "Workflow run", Icon: nerdfonts.WorkflowRun, Urgency: "normal", Priority: 8},
+}
+
+// unknownPriority sorts reasons and types GitHub adds later after known ones
+const unknownPriority = 99
+
+// Reason returns the entry for a notification reason. Unknown reasons get a
+// label derived from their name, e.g. "new_thing" becomes "New thing".
+func Reason(reason string) Entry {
+	if entry, ok := reasons[reason]; ok {
+		return entry
+	}
+
+	label := "Notification"
+	if reason != "" {
+		label = strings.ReplaceAll(reason, "_", " ")
+		label = strings.ToUpper(label[:1]) + label[1:]
+	}
+
+	return Entry{Label: label, Urgency: "normal", Priority: unknownPriority}
+}
+
+// Type returns the entry for a notification subject type. Unknown types get a
+// label derived from their name, e.g. "NewThing" becomes "New thing".
+func Type(subjectType string) Entry {
+	if entry, ok := types[subjectType]; ok {
+		return entry
+	}
+
+	label := "Unknown"
+	if subjectType != "" {
+		label = splitCamelCase(subjectType)
+	}
+
+	return Entry{Label: label, Icon: nerdfonts.DefaultNotif, Urgency: "normal", Priority: unknownPriority}
+}
+
+// Icon returns the reason icon when the reason has one, otherwise the
+// subject type icon
+func Icon(reason, subjectType string) string {
+	if icon := Reason(reason).Icon; icon != "" {
+		return icon
+	}
+	return Type(subjectType).Icon
+}
+
+// Urgency returns the higher default urgency of the reason and subject type
+func Urgency(reason, subjectType string) string {
+	return HigherUrgency(Reason(reason).Urgency, Type(subjectType).Urgency)
+}
+
+// HigherUrgency returns the more urgent of two urgencies. An empty or unknown
+// urgency ranks below low.
+func HigherUrgency(a, b string) string {
+	if urgencyRank[b] > urgencyRank[a] {
+		return b
+	}
+	return a
+}
+
+// Compare orders notifications by reason priority, then subject type
+// priority. It returns a negative number when a sorts before b, a positive
+// number when b sorts before a, and zero when they have the same priority.
+func Compare(reasonA, typeA, reasonB, typeB string) int {
+	if c := Reason(reasonA).Priority - Reason(reasonB).Priority; c != 0 {
+		return c
+	}
+	return Type(typeA).Priority - Type(typeB).Priority
+}
+
+// splitCamelCase turns "CheckSuite" into "Check suite"
+func splitCamelCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && unicode.IsUpper(r) {
+			b.WriteRune(' ')
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
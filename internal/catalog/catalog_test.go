@@ -0,0 +1,89 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/bnema/gh-notify/internal/nerdfonts"
+)
+
+// TestCatalogComplete tests that every known reason and type has a label, urgency and icon
+func TestCatalogComplete(t *testing.T) {
+	for reason, entry := range reasons {
+		if entry.Label == "" || entry.Urgency == "" {
+			t.Errorf("Reason %s is missing a label or urgency: %+v", reason, entry)
+		}
+	}
+
+	for subjectType, entry := range types {
+		if entry.Label == "" || entry.Urgency == "" || entry.Icon == "" {
+			t.Errorf("Type %s is missing a label, urgency or icon: %+v", subjectType, entry)
+		}
+	}
+
+	t.Logf("✓ Catalog completeness test passed!")
+}
+
+// TestUnknownEntries tests the fallbacks for reasons and types GitHub adds later
+func TestUnknownEntries(t *testing.T) {
+	if label := Reason("new_thing").Label; label != "New thing" {
+		t.Errorf("Expected 'New thing', got %q", label)
+	}
+	if label := Reason("").Label; label != "Notification" {
+		t.Errorf("Expected 'Notification', got %q", label)
+	}
+	if label := Type("NewThing").Label; label != "New thing" {
+		t.Errorf("Expected 'New thing', got %q", label)
+	}
+	if label := Type("").Label; label != "Unknown" {
+		t.Errorf("Expected 'Unknown', got %q", label)
+	}
+	if Compare("new_thing", "", "subscribed", "Issue") <= 0 {
+		t.Error("Expected unknown reasons to sort after known ones")
+	}
+
+	t.Logf("✓ Unknown entries test passed!")
+}
+
+// TestIcon tests that reason icons win over subject type icons
+func TestIcon(t *testing.T) {
+	tests := []struct {
+		reason, subjectType, expected string
+	}{
+		{"review_requested", "PullRequest", nerdfonts.ReviewRequested},
+		{"subscribed", "Discussion", nerdfonts.Discussion},
+		{"ci_activity", "CheckSuite", nerdfonts.CheckSuite},
+		{"comment", "Mystery", nerdfonts.DefaultNotif},
+	}
+
+	for _, tt := range tests {
+		if got := Icon(tt.reason, tt.subjectType); got != tt.expected {
+			t.Errorf("Icon(%s, %s) = %q, want %q", tt.reason, tt.subjectType, got, tt.expected)
+		}
+	}
+
+	t.Logf("✓ Icon test passed!")
+}
+
+// TestUrgencyAndPriority tests default urgencies and sort order
+func TestUrgencyAndPriority(t *testing.T) {
+	if got := Urgency("subscribed", "RepositoryVulnerabilityAlert"); got != "critical" {
+		t.Errorf("Expected type urgency to raise the default, got %q", got)
+	}
+	if got := Urgency("mention", "Issue"); got != "normal" {
+		t.Errorf("Expected normal urgency, got %q", got)
+	}
+	if got := HigherUrgency("", "low"); got != "low" {
+		t.Errorf("Expected any urgency to beat none, got %q", got)
+	}
+	if got := HigherUrgency("critical", "normal"); got != "critical" {
+		t.Errorf("Expected critical to beat normal, got %q", got)
+	}
+	if Compare("review_requested", "PullRequest", "subscribed", "PullRequest") >= 0 {
+		t.Error("Expected review requests to sort before subscriptions")
+	}
+	if Compare("subscribed", "PullRequest", "subscribed", "Release") >= 0 {
+		t.Error("Expected pull requests to sort before releases for the same reason")
+	}
+
+	t.Logf("✓ Urgency and priority test passed!")
+}
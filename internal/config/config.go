@@ -0,0 +1,382 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/notifytemplate"
+	"github.com/bnema/gh-notify/internal/quiethours"
+	"github.com/bnema/gh-notify/internal/rules"
+	"github.com/bnema/gh-notify/internal/sink"
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds user settings loaded from the config file.
+//
+// Values are resolved with the following precedence (highest first):
+// command-line flags, GH_NOTIFY_* environment variables, the config file,
+// and finally the built-in defaults returned by Default.
+type Config struct {
+	CacheDir string         `yaml:"cache_dir"`
+	Hostname string         `yaml:"hostname"` // GitHub host, e.g. a GitHub Enterprise Server
+	Sync     SyncConfig     `yaml:"sync"`
+	Notifier NotifierConfig `yaml:"notifier"`
+	Waybar   WaybarConfig   `yaml:"waybar"`
+	List     ListConfig     `yaml:"list"`
+	Service  ServiceConfig  `yaml:"service"`
+	// Rules filter notifications during sync, in list and in the waybar output
+	Rules      []rules.Rule     `yaml:"rules"`
+	QuietHours QuietHoursConfig `yaml:"quiet_hours"`
+	// Accounts polled by sync; empty means gh's active account on Hostname
+	Accounts []AccountConfig `yaml:"accounts"`
+	// Sinks receive new notifications and stars alongside desktop alerts
+	Sinks   []sink.Config `yaml:"sinks"`
+	History HistoryConfig `yaml:"history"`
+	Storage StorageConfig `yaml:"storage"`
+
+	// compiled is built by Validate
+	compiled Compiled
+}
+
+// Compiled holds the rules, quiet hours, sinks and templates Validate builds
+// from the config, so they are compiled once
+type Compiled struct {
+	Rules      *rules.Engine
+	QuietHours *quiethours.Schedule
+	Sinks      *sink.Set
+	Templates  *notifytemplate.Templates
+}
+
+// AccountConfig is one gh-authenticated account polled alongside the others
+type AccountConfig struct {
+	Name     string `yaml:"name"`     // Shown in list, waybar and alerts; also the cache subdirectory
+	Hostname string `yaml:"hostname"` // Defaults to gh's default host
+	User     string `yaml:"user"`     // gh login on the host; defaults to the active account
+}
+
+// SyncConfig controls how notifications and stars are fetched
+type SyncConfig struct {
+	Since             time.Duration `yaml:"since"`
+	ExcludeStars      bool          `yaml:"exclude_stars"`
+	StarFetchInterval time.Duration `yaml:"star_fetch_interval"`
+}
+
+// NotifierConfig controls desktop notifications
+type NotifierConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Urgency string `yaml:"urgency"` // Overrides the per-reason urgency when set
+	Backend string `yaml:"backend"` // auto, dbus or notify-send
+	// Summary keeps one "GitHub: N unread" notification, updated in place,
+	// instead of alerting on each new notification
+	Summary bool `yaml:"summary"`
+	// Templates replace the built-in notification text
+	Templates notifytemplate.Config `yaml:"templates"`
+}
+
+// WaybarConfig controls the waybar JSON output
+type WaybarConfig struct {
+	StarWindow    time.Duration `yaml:"star_window"`
+	MaxLineLength int           `yaml:"max_line_length"`
+}
+
+// HistoryConfig controls the history of read and unread threads
+type HistoryConfig struct {
+	Retention time.Duration `yaml:"retention"` // How long read threads are kept
+}
+
+// StorageConfig controls where the cache is kept
+type StorageConfig struct {
+	Backend    string `yaml:"backend"`     // json or sqlite
+	MaxEntries int    `yaml:"max_entries"` // Cap on cached notifications and stars (JSON backend)
+}
+
+// QuietHoursConfig holds the do-not-disturb schedule for desktop alerts
+type QuietHoursConfig struct {
+	Windows []quiethours.Window `yaml:"windows"`
+	// AllowReasons still alert during quiet hours, e.g. security_alert
+	AllowReasons []string `yaml:"allow_reasons"`
+}
+
+// ListConfig holds default filters for list and open
+type ListConfig struct {
+	Limit      int    `yaml:"limit"`
+	Repository string `yaml:"repository"`
+	Reason     string `yaml:"reason"`
+}
+
+// ServiceConfig controls the systemd service installed by install-service
+type ServiceConfig struct {
+	Interval time.Duration `yaml:"interval"`
+}
+
+const (
+	// DefaultStarFetchInterval limits star fetching to avoid GraphQL API limits
+	DefaultStarFetchInterval = 1 * time.Hour
+	// DefaultWaybarStarWindow is how far back stars are shown in the waybar tooltip
+	DefaultWaybarStarWindow = 1 * time.Hour
+	// EnvPrefix is the prefix for environment variables overriding config values
+	EnvPrefix = "GH_NOTIFY_"
+	// MinServiceInterval is the lowest sync interval allowed by GitHub API polling guidelines
+	MinServiceInterval = 60 * time.Second
+)
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		Sync: SyncConfig{
+			StarFetchInterval: DefaultStarFetchInterval,
+		},
+		Notifier: NotifierConfig{
+			Enabled: true,
+			Backend: "auto",
+		},
+		Waybar: WaybarConfig{
+			StarWindow:    DefaultWaybarStarWindow,
+			MaxLineLength: 80,
+		},
+		List: ListConfig{
+			Limit: 20,
+		},
+		Service: ServiceConfig{
+			Interval: MinServiceInterval,
+		},
+		History: HistoryConfig{
+			Retention: cache.DefaultHistoryRetention,
+		},
+		Storage: StorageConfig{
+			Backend:    cache.BackendJSON,
+			MaxEntries: cache.DefaultMaxEntries,
+		},
+	}
+}
+
+// DefaultPath returns the default config file location, following the XDG
+// base directory spec: $XDG_CONFIG_HOME/gh-notify/config.yaml
+func DefaultPath() (string, error) {
+	configDir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user config directory: %w", err)
+	}
+
+	return filepath.Join(configDir, "gh-notify", "config.yaml"), nil
+}
+
+// Load reads the config file at path on top of the defaults and applies
+// environment overrides. When path is empty the default location is used
+// and a missing file is not an error; an explicitly given path must exist.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	explicit := path != ""
+	if !explicit {
+		defaultPath, err := DefaultPath()
+		if err != nil {
+			return nil, err
+		}
+		path = defaultPath
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) || explicit {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+	} else if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	cfg.CacheDir, err = expandHome(cfg.CacheDir)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// applyEnv overrides config values with GH_NOTIFY_<SECTION>_<KEY> environment variables
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	durations := map[string]*time.Duration{
+		"SYNC_SINCE":               &c.Sync.Since,
+		"SYNC_STAR_FETCH_INTERVAL": &c.Sync.StarFetchInterval,
+		"WAYBAR_STAR_WINDOW":       &c.Waybar.StarWindow,
+		"SERVICE_INTERVAL":         &c.Service.Interval,
+		"HISTORY_RETENTION":        &c.History.Retention,
+	}
+	bools := map[string]*bool{
+		"SYNC_EXCLUDE_STARS": &c.Sync.ExcludeStars,
+		"NOTIFIER_ENABLED":   &c.Notifier.Enabled,
+		"NOTIFIER_SUMMARY":   &c.Notifier.Summary,
+	}
+	ints := map[string]*int{
+		"LIST_LIMIT":             &c.List.Limit,
+		"WAYBAR_MAX_LINE_LENGTH": &c.Waybar.MaxLineLength,
+		"STORAGE_MAX_ENTRIES":    &c.Storage.MaxEntries,
+	}
+	strs := map[string]*string{
+		"CACHE_DIR":        &c.CacheDir,
+		"HOSTNAME":         &c.Hostname,
+		"NOTIFIER_URGENCY": &c.Notifier.Urgency,
+		"NOTIFIER_BACKEND": &c.Notifier.Backend,
+		"LIST_REPOSITORY":  &c.List.Repository,
+		"LIST_REASON":      &c.List.Reason,
+		"STORAGE_BACKEND":  &c.Storage.Backend,
+	}
+
+	for key, target := range durations {
+		if value, ok := lookup(EnvPrefix + key); ok {
+			d, err := time.ParseDuration(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s%s: %w", EnvPrefix, key, err)
+			}
+			*target = d
+		}
+	}
+
+	for key, target := range bools {
+		if value, ok := lookup(EnvPrefix + key); ok {
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s%s: %w", EnvPrefix, key, err)
+			}
+			*target = b
+		}
+	}
+
+	for key, target := range ints {
+		if value, ok := lookup(EnvPrefix + key); ok {
+			n, err := strconv.Atoi(value)
+			if err != nil {
+				return fmt.Errorf("invalid %s%s: %w", EnvPrefix, key, err)
+			}
+			*target = n
+		}
+	}
+
+	for key, target := range strs {
+		if value, ok := lookup(EnvPrefix + key); ok {
+			*target = value
+		}
+	}
+
+	return nil
+}
+
+// Validate checks that config values are usable, keeping the rules, quiet
+// hours, sinks and templates it compiles for Compiled
+func (c *Config) Validate() error {
+	switch c.Notifier.Urgency {
+	case "", "low", "normal", "critical":
+	default:
+		return fmt.Errorf("notifier.urgency must be one of low, normal, critical (got %q)", c.Notifier.Urgency)
+	}
+
+	switch c.Notifier.Backend {
+	case "", "auto", "dbus", "notify-send":
+	default:
+		return fmt.Errorf("notifier.backend must be one of auto, dbus, notify-send (got %q)", c.Notifier.Backend)
+	}
+
+	templates, err := notifytemplate.New(c.Notifier.Templates)
+	if err != nil {
+		return fmt.Errorf("notifier.templates.%w", err)
+	}
+
+	if c.Sync.Since < 0 {
+		return fmt.Errorf("sync.since must not be negative")
+	}
+
+	if c.Sync.StarFetchInterval <= 0 {
+		return fmt.Errorf("sync.star_fetch_interval must be positive")
+	}
+
+	if c.Waybar.StarWindow <= 0 {
+		return fmt.Errorf("waybar.star_window must be positive")
+	}
+
+	if c.Waybar.MaxLineLength < 10 {
+		return fmt.Errorf("waybar.max_line_length must be at least 10")
+	}
+
+	if c.History.Retention <= 0 {
+		return fmt.Errorf("history.retention must be positive")
+	}
+
+	switch c.Storage.Backend {
+	case "", cache.BackendJSON, cache.BackendSQLite:
+	default:
+		return fmt.Errorf("storage.backend must be one of json, sqlite (got %q)", c.Storage.Backend)
+	}
+
+	if c.Storage.MaxEntries <= 0 {
+		return fmt.Errorf("storage.max_entries must be positive")
+	}
+
+	if c.Service.Interval < MinServiceInterval {
+		return fmt.Errorf("service.interval must be at least %v to respect GitHub API polling guidelines", MinServiceInterval)
+	}
+
+	sinks, err := sink.New(c.Sinks)
+	if err != nil {
+		return err
+	}
+
+	engine, err := rules.New(c.Rules)
+	if err != nil {
+		return fmt.Errorf("rules: %w", err)
+	}
+
+	schedule, err := quiethours.New(c.QuietHours.Windows)
+	if err != nil {
+		return fmt.Errorf("quiet_hours: %w", err)
+	}
+
+	names := make(map[string]bool)
+	for i, account := range c.Accounts {
+		switch {
+		case account.Name == "":
+			return fmt.Errorf("accounts[%d].name is required", i)
+		case account.Name == "." || account.Name == ".." || strings.ContainsAny(account.Name, `/\`):
+			return fmt.Errorf("accounts[%d].name %q must be usable as a directory name", i, account.Name)
+		case names[account.Name]:
+			return fmt.Errorf("accounts[%d].name %q is used more than once", i, account.Name)
+		}
+		names[account.Name] = true
+	}
+
+	c.compiled = Compiled{Rules: engine, QuietHours: schedule, Sinks: sinks, Templates: templates}
+
+	return nil
+}
+
+// Compiled returns what the last successful Validate compiled. Load
+// validates, so a loaded config always has it.
+func (c *Config) Compiled() Compiled {
+	return c.compiled
+}
+
+// expandHome replaces a leading ~/ with the user's home directory
+func expandHome(path string) (string, error) {
+	if !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get user home directory: %w", err)
+	}
+
+	return filepath.Join(homeDir, path[2:]), nil
+}
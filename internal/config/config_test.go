@@ -0,0 +1,242 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeConfig writes a config file into a temp dir and returns its path
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// TestLoad_MissingDefaultFile tests that a missing default config falls back to defaults
+func TestLoad_MissingDefaultFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Expected no error for missing default config, got %v", err)
+	}
+
+	if cfg.Sync.StarFetchInterval != DefaultStarFetchInterval {
+		t.Errorf("Expected default star fetch interval %v, got %v", DefaultStarFetchInterval, cfg.Sync.StarFetchInterval)
+	}
+
+	if !cfg.Notifier.Enabled {
+		t.Error("Expected notifications to be enabled by default")
+	}
+
+	t.Logf("✓ Missing default config test passed!")
+}
+
+// TestLoad_MissingExplicitFile tests that an explicitly given config must exist
+func TestLoad_MissingExplicitFile(t *testing.T) {
+	_, err := Load(filepath.Join(t.TempDir(), "nope.yaml"))
+	if err == nil {
+		t.Fatal("Expected error for missing explicit config file, got nil")
+	}
+
+	t.Logf("✓ Missing explicit config test passed!")
+}
+
+// TestLoad_FileOverridesDefaults tests that file values replace defaults and keep unset ones
+func TestLoad_FileOverridesDefaults(t *testing.T) {
+	path := writeConfig(t, `
+sync:
+  since: 2h
+  exclude_stars: true
+notifier:
+  urgency: low
+list:
+  limit: 50
+  repository: cli/cli
+rules:
+  - repository: my-org/*
+    reason: subscribed
+    action: silent
+`)
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Sync.Since != 2*time.Hour {
+		t.Errorf("Expected since 2h, got %v", cfg.Sync.Since)
+	}
+	if !cfg.Sync.ExcludeStars {
+		t.Error("Expected exclude_stars to be true")
+	}
+	if cfg.Notifier.Urgency != "low" {
+		t.Errorf("Expected urgency 'low', got %q", cfg.Notifier.Urgency)
+	}
+	if cfg.List.Limit != 50 || cfg.List.Repository != "cli/cli" {
+		t.Errorf("Expected list limit 50 and repository cli/cli, got %d and %q", cfg.List.Limit, cfg.List.Repository)
+	}
+
+	if len(cfg.Rules) != 1 || cfg.Rules[0].Repository != "my-org/*" || cfg.Rules[0].Action != "silent" {
+		t.Errorf("Expected one silent rule for my-org/*, got %+v", cfg.Rules)
+	}
+	// Validation keeps what it compiled
+	if compiled := cfg.Compiled(); compiled.Rules == nil || compiled.Templates == nil {
+		t.Errorf("Expected compiled rules and templates, got %+v", compiled)
+	}
+
+	// Values missing from the file keep their defaults
+	if !cfg.Notifier.Enabled {
+		t.Error("Expected notifier.enabled to keep its default")
+	}
+	if cfg.Waybar.StarWindow != DefaultWaybarStarWindow {
+		t.Errorf("Expected default star window, got %v", cfg.Waybar.StarWindow)
+	}
+
+	t.Logf("✓ File overrides defaults test passed!")
+}
+
+// TestLoad_EnvOverridesFile tests that environment variables take precedence over the file
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	path := writeConfig(t, `
+sync:
+  since: 2h
+notifier:
+  enabled: true
+list:
+  limit: 50
+`)
+
+	t.Setenv("GH_NOTIFY_SYNC_SINCE", "30m")
+	t.Setenv("GH_NOTIFY_NOTIFIER_ENABLED", "false")
+	t.Setenv("GH_NOTIFY_LIST_LIMIT", "5")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if cfg.Sync.Since != 30*time.Minute {
+		t.Errorf("Expected env since 30m, got %v", cfg.Sync.Since)
+	}
+	if cfg.Notifier.Enabled {
+		t.Error("Expected env to disable notifications")
+	}
+	if cfg.List.Limit != 5 {
+		t.Errorf("Expected env list limit 5, got %d", cfg.List.Limit)
+	}
+
+	t.Logf("✓ Env overrides file test passed!")
+}
+
+// TestLoad_InvalidValues tests validation and parse errors
+func TestLoad_InvalidValues(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		env     map[string]string
+		wantErr string
+	}{
+		{
+			name:    "bad urgency",
+			content: "notifier:\n  urgency: loud\n",
+			wantErr: "notifier.urgency",
+		},
+		{
+			name:    "sink without command",
+			content: "sinks:\n  - name: script\n    type: exec\n",
+			wantErr: "sink script",
+		},
+		{
+			name:    "webhook with invalid template",
+			content: "sinks:\n  - type: webhook\n    url: https://example.com/hook\n    template: '{{ .Count'\n",
+			wantErr: "sink #1: invalid template",
+		},
+		{
+			name:    "invalid notification template",
+			content: "notifier:\n  templates:\n    star:\n      body: '{{ .Starz }}'\n",
+			wantErr: "notifier.templates.star.body",
+		},
+		{
+			name:    "bad backend",
+			content: "notifier:\n  backend: kdialog\n",
+			wantErr: "notifier.backend",
+		},
+		{
+			name:    "bad storage backend",
+			content: "storage:\n  backend: postgres\n",
+			wantErr: "storage.backend",
+		},
+		{
+			name:    "interval below minimum",
+			content: "service:\n  interval: 10s\n",
+			wantErr: "service.interval",
+		},
+		{
+			name:    "malformed duration",
+			content: "sync:\n  since: soon\n",
+			wantErr: "failed to parse config file",
+		},
+		{
+			name:    "malformed env duration",
+			content: "",
+			env:     map[string]string{"GH_NOTIFY_SYNC_SINCE": "soon"},
+			wantErr: "GH_NOTIFY_SYNC_SINCE",
+		},
+		{
+			name:    "bad rule action",
+			content: "rules:\n  - name: noisy\n    action: hide\n",
+			wantErr: "rule noisy",
+		},
+		{
+			name:    "bad rule title pattern",
+			content: "rules:\n  - title: \"[\"\n    action: drop\n",
+			wantErr: "invalid title pattern",
+		},
+		{
+			name:    "bad quiet hours window",
+			content: "quiet_hours:\n  windows:\n    - start: \"22:00\"\n      end: \"7am\"\n",
+			wantErr: "quiet_hours",
+		},
+		{
+			name:    "account without name",
+			content: "accounts:\n  - hostname: ghe.example.com\n",
+			wantErr: "accounts[0].name is required",
+		},
+		{
+			name:    "duplicate account names",
+			content: "accounts:\n  - name: work\n  - name: work\n",
+			wantErr: "used more than once",
+		},
+		{
+			name:    "account name with path separator",
+			content: "accounts:\n  - name: ../work\n",
+			wantErr: "directory name",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for key, value := range tc.env {
+				t.Setenv(key, value)
+			}
+
+			_, err := Load(writeConfig(t, tc.content))
+			if err == nil {
+				t.Fatal("Expected error, got nil")
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	t.Logf("✓ Invalid values test passed!")
+}
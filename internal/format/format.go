@@ -0,0 +1,32 @@
+// Package format renders ages and shortened text the same way in list,
+// history, the waybar tooltip and notification templates.
+package format
+
+import (
+	"fmt"
+	"time"
+)
+
+// Age formats a duration in its largest whole unit, e.g. "45s", "5m", "3h"
+// or "2d"
+func Age(duration time.Duration) string {
+	switch {
+	case duration < time.Minute:
+		return fmt.Sprintf("%ds", int(duration.Seconds()))
+	case duration < time.Hour:
+		return fmt.Sprintf("%dm", int(duration.Minutes()))
+	case duration < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(duration.Hours()))
+	default:
+		return fmt.Sprintf("%dd", int(duration.Hours()/24))
+	}
+}
+
+// Truncate shortens s to at most length runes, ending with "…" when cut
+func Truncate(s string, length int) string {
+	runes := []rune(s)
+	if length < 1 || len(runes) <= length {
+		return s
+	}
+	return string(runes[:length-1]) + "…"
+}
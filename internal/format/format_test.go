@@ -0,0 +1,49 @@
+package format
+
+import (
+	"testing"
+	"time"
+)
+
+// TestAge tests that ages use their largest whole unit
+func TestAge(t *testing.T) {
+	tests := []struct {
+		duration time.Duration
+		expected string
+	}{
+		{45 * time.Second, "45s"},
+		{5 * time.Minute, "5m"},
+		{3*time.Hour + 59*time.Minute, "3h"},
+		{50 * time.Hour, "2d"},
+	}
+
+	for _, tt := range tests {
+		if got := Age(tt.duration); got != tt.expected {
+			t.Errorf("Age(%v) = %q, want %q", tt.duration, got, tt.expected)
+		}
+	}
+
+	t.Logf("✓ Age format test passed!")
+}
+
+// TestTruncate tests that text is cut on a character boundary
+func TestTruncate(t *testing.T) {
+	tests := []struct {
+		s        string
+		length   int
+		expected string
+	}{
+		{"Fix login", 20, "Fix login"},
+		{"Fix the login page", 10, "Fix the l…"},
+		{"Réécrire", 4, "Réé…"},
+		{"Fix login", 0, "Fix login"},
+	}
+
+	for _, tt := range tests {
+		if got := Truncate(tt.s, tt.length); got != tt.expected {
+			t.Errorf("Truncate(%q, %d) = %q, want %q", tt.s, tt.length, got, tt.expected)
+		}
+	}
+
+	t.Logf("✓ Truncate test passed!")
+}
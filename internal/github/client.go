@@ -2,37 +2,95 @@ package github
 
 import (
 	"fmt"
+	"strings"
 
+	gh "github.com/cli/go-gh/v2"
 	"github.com/cli/go-gh/v2/pkg/api"
+	"github.com/cli/go-gh/v2/pkg/auth"
 )
 
 // Client is the main GitHub API client that wraps REST and GraphQL clients
 type Client struct {
 	restClient    RESTClient
 	graphqlClient GraphQLClient
+	host          string
 }
 
 // Ensure Client implements GitHubClientInterface
 var _ GitHubClientInterface = (*Client)(nil)
 
-// NewClient creates a new GitHub API client using default gh CLI authentication
-func NewClient() (*Client, error) {
-	restClient, err := api.DefaultRESTClient()
+// NewClient creates a new GitHub API client using gh CLI authentication for
+// host, e.g. a GitHub Enterprise Server hostname. An empty host uses gh's
+// default host (github.com, or GH_HOST when set). An empty user uses the
+// host's active gh account; otherwise that account's token is used.
+func NewClient(host, user string) (*Client, error) {
+	if host == "" {
+		host, _ = auth.DefaultHost()
+	}
+	host = auth.NormalizeHostname(host)
+
+	opts := api.ClientOptions{Host: host}
+	if user != "" {
+		token, err := userToken(host, user)
+		if err != nil {
+			return nil, err
+		}
+		opts.AuthToken = token
+	}
+
+	restClient, err := api.NewRESTClient(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitHub REST client: %w", err)
 	}
 
-	graphqlClient, err := api.DefaultGraphQLClient()
+	httpClient, err := api.NewHTTPClient(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GitHub HTTP client: %w", err)
+	}
+
+	// go-gh resolves the GraphQL endpoint per host (/api/graphql on GHES)
+	graphqlClient, err := api.NewGraphQLClient(opts)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GitHub GraphQL client: %w", err)
 	}
 
 	return &Client{
-		restClient:    &apiRESTClient{client: restClient},
+		restClient: &apiRESTClient{
+			client:     restClient,
+			httpClient: httpClient,
+			baseURL:    restBaseURL(host),
+		},
 		graphqlClient: &apiGraphQLClient{client: graphqlClient},
+		host:          host,
 	}, nil
 }
 
+// userToken asks gh for the token of a specific account, which may not be the
+// active one on the host
+func userToken(host, user string) (string, error) {
+	stdout, stderr, err := gh.Exec("auth", "token", "--hostname", host, "--user", user)
+	if err != nil {
+		return "", fmt.Errorf("failed to get gh token for %s on %s: %w: %s", user, host, err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// Host returns the GitHub hostname the client talks to
+func (c *Client) Host() string {
+	return c.host
+}
+
+// restBaseURL returns the REST API root for a host, matching go-gh's resolution:
+// github.com uses api.github.com, GitHub Enterprise Server uses /api/v3/
+func restBaseURL(host string) string {
+	host = auth.NormalizeHostname(host)
+	if auth.IsEnterprise(host) {
+		return fmt.Sprintf("https://%s/api/v3/", host)
+	}
+	return fmt.Sprintf("https://api.%s/", host)
+}
+
 // NewTestClient creates a client with injected dependencies for testing
 func NewTestClient(restClient RESTClient, graphqlClient GraphQLClient) *Client {
 	return &Client{
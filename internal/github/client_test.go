@@ -370,3 +370,44 @@ func TestHelpers_OwnerAndName(t *testing.T) {
 
 	t.Logf("✓ Helper functions test passed!")
 }
+
+// TestConvertAPIURLToWeb tests URL conversion for github.com and GitHub Enterprise Server
+func TestConvertAPIURLToWeb(t *testing.T) {
+	testCases := []struct {
+		apiURL   string
+		expected string
+	}{
+		{"https://api.github.com/repos/owner/repo/issues/123", "https://github.com/owner/repo/issues/123"},
+		{"https://api.github.com/repos/owner/repo/pulls/42", "https://github.com/owner/repo/pull/42"},
+		{"https://api.github.com/repos/owner/repo/commits/abc123", "https://github.com/owner/repo/commit/abc123"},
+		{"https://api.github.com/repos/owner/repo/releases/555", "https://github.com/owner/repo/releases"},
+		{"https://api.github.com/repos/owner/repo/check-suites/1", "https://github.com/owner/repo"},
+		{"https://ghe.example.com/api/v3/repos/owner/repo/pulls/7", "https://ghe.example.com/owner/repo/pull/7"},
+		{"https://ghe.example.com/api/v3/repos/owner/repo/issues/8", "https://ghe.example.com/owner/repo/issues/8"},
+		{"https://ghe.example.com/api/v3/repos/owner/repo/releases/9", "https://ghe.example.com/owner/repo/releases"},
+		{"https://api.tenant.ghe.com/repos/owner/repo/issues/1", "https://tenant.ghe.com/owner/repo/issues/1"},
+		{"https://example.com/something", "https://example.com/something"},
+		{"", ""},
+	}
+
+	for _, tc := range testCases {
+		if got := ConvertAPIURLToWeb(tc.apiURL); got != tc.expected {
+			t.Errorf("ConvertAPIURLToWeb(%q) = %q, want %q", tc.apiURL, got, tc.expected)
+		}
+	}
+
+	t.Logf("✓ URL conversion test passed!")
+}
+
+// TestRestBaseURL tests REST API roots for github.com and GitHub Enterprise Server
+func TestRestBaseURL(t *testing.T) {
+	if got := restBaseURL("github.com"); got != "https://api.github.com/" {
+		t.Errorf("Expected api.github.com root, got %q", got)
+	}
+
+	if got := restBaseURL("ghe.example.com"); got != "https://ghe.example.com/api/v3/" {
+		t.Errorf("Expected GHES /api/v3/ root, got %q", got)
+	}
+
+	t.Logf("✓ REST base URL test passed!")
+}
@@ -2,7 +2,10 @@ package github
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net/http"
 	"strings"
 	"time"
 
@@ -13,9 +16,15 @@ import (
 
 // GitHubClientInterface defines the main client interface for testing
 type GitHubClientInterface interface {
-	FetchNotifications() ([]cache.CacheEntry, error)
+	FetchNotifications(lastModified string) (*NotificationsResult, error)
 	FetchRecentStars(since time.Time) ([]cache.StarEvent, error)
 	GetAuthenticatedUser() (string, error)
+	MarkThreadRead(threadID string) error
+	MarkAllRead(repository string, lastReadAt time.Time) error
+	MuteThread(threadID string) error
+	UnmuteThread(threadID string) error
+	ResolveWebURLs(entries, cached []cache.CacheEntry) []cache.CacheEntry
+	EnrichSubjects(entries, cached []cache.CacheEntry) []cache.CacheEntry
 	TestAuth() error
 }
 
@@ -27,17 +36,86 @@ type GraphQLClient interface {
 // RESTClient wraps the external REST client for mocking
 type RESTClient interface {
 	Get(path string, response interface{}) error
+	// Request sends the given headers and returns the raw response so callers
+	// can read response headers. path may also be an absolute URL, such as a
+	// Link pagination URL. A 304 Not Modified response is returned as a
+	// response rather than an error; other non-2xx statuses are errors.
+	Request(method string, path string, headers http.Header, body io.Reader) (*http.Response, error)
+	// Do sends a write request (PATCH, PUT, DELETE, ...) and decodes the JSON
+	// response into response when both are non-empty
+	Do(method string, path string, body io.Reader, response interface{}) error
 }
 
-// apiRESTClient wraps api.RESTClient to implement RESTClient interface
+// apiRESTClient wraps api.RESTClient to implement RESTClient interface.
+// Requests needing custom headers go through httpClient, which carries the
+// same gh authentication as client.
 type apiRESTClient struct {
-	client *api.RESTClient
+	client     *api.RESTClient
+	httpClient *http.Client
+	baseURL    string
 }
 
 func (c *apiRESTClient) Get(path string, response interface{}) error {
 	return c.client.Get(path, response)
 }
 
+func (c *apiRESTClient) Request(method string, path string, headers http.Header, body io.Reader) (*http.Response, error) {
+	url := path
+	if !strings.HasPrefix(path, "https://") && !strings.HasPrefix(path, "http://") {
+		url = c.baseURL + path
+	}
+
+	req, err := http.NewRequest(method, url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, values := range headers {
+		req.Header[key] = values
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusNotModified {
+		return resp, nil
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer func() { _ = resp.Body.Close() }()
+		return nil, api.HandleHTTPError(resp)
+	}
+
+	return resp, nil
+}
+
+func (c *apiRESTClient) Do(method string, path string, body io.Reader, response interface{}) error {
+	var headers http.Header
+	if body != nil {
+		headers = http.Header{"Content-Type": []string{"application/json"}}
+	}
+
+	resp, err := c.Request(method, path, headers, body)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// Write endpoints often answer 202/204/205 with an empty body
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	if response == nil || len(data) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal(data, response)
+}
+
 // apiGraphQLClient wraps api.GraphQLClient to implement GraphQLClient interface with retry logic
 type apiGraphQLClient struct {
 	client *api.GraphQLClient
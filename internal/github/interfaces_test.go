@@ -2,6 +2,8 @@ package github
 
 import (
 	"errors"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
@@ -162,3 +164,86 @@ func TestApiGraphQLClient_Timeout(t *testing.T) {
 		t.Errorf("expected ~30s timeout but got %v", duration)
 	}
 }
+
+func TestApiRESTClient_Request(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/notifications":
+			if r.Header.Get("If-Modified-Since") != "" {
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("Last-Modified", "Wed, 01 Jan 2025 10:00:00 GMT")
+			_, _ = w.Write([]byte("[]"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := &apiRESTClient{httpClient: server.Client(), baseURL: server.URL + "/"}
+
+	tests := []struct {
+		name       string
+		path       string
+		headers    http.Header
+		wantStatus int
+		wantErr    bool
+	}{
+		{
+			name:       "success",
+			path:       "notifications",
+			wantStatus: http.StatusOK,
+		},
+		{
+			name:       "not modified is not an error",
+			path:       "notifications",
+			headers:    http.Header{"If-Modified-Since": []string{"Wed, 01 Jan 2025 10:00:00 GMT"}},
+			wantStatus: http.StatusNotModified,
+		},
+		{
+			name:    "not found is an error",
+			path:    "missing",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client.Request(http.MethodGet, tt.path, tt.headers, nil)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("expected error but got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("expected no error but got: %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			if resp.StatusCode != tt.wantStatus {
+				t.Errorf("expected status %d but got %d", tt.wantStatus, resp.StatusCode)
+			}
+		})
+	}
+}
+
+func TestApiRESTClient_DoEmptyBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPatch {
+			t.Errorf("expected PATCH but got %s", r.Method)
+		}
+		// Marking a thread read answers 205 Reset Content with no body
+		w.WriteHeader(http.StatusResetContent)
+	}))
+	defer server.Close()
+
+	client := &apiRESTClient{httpClient: server.Client(), baseURL: server.URL + "/"}
+
+	var response map[string]interface{}
+	if err := client.Do(http.MethodPatch, "notifications/threads/1", nil, &response); err != nil {
+		t.Fatalf("expected no error for empty body but got: %v", err)
+	}
+}
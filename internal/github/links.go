@@ -0,0 +1,163 @@
+package github
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/logger"
+)
+
+// maxURLResolutionsPerSync bounds the html_url lookups made by one
+// ResolveWebURLs call; remaining entries are resolved on later syncs
+const maxURLResolutionsPerSync = 50
+
+// discussionTargetPrefix marks ResolvedFrom values of discussions, which are
+// found by title as their notifications carry no subject URL
+const discussionTargetPrefix = "discussion:"
+
+// discussionSearchQuery finds discussions of a repository by title
+const discussionSearchQuery = `query($search: String!) {
+	search(query: $search, type: DISCUSSION, first: 10) {
+		nodes {
+			... on Discussion {
+				title
+				url
+			}
+		}
+	}
+}`
+
+// ResolveWebURLs replaces the web URLs derived from API URLs with the real
+// html_url of each notification's target: the latest comment when there is
+// one (for #issuecomment-... anchors), otherwise the subject, such as a
+// release with its tag name. Discussions are searched by title instead.
+// Entries already resolved from the same target in cached are reused without
+// an API call. Lookups that fail keep the derived URL and are retried on the
+// next sync.
+func (c *Client) ResolveWebURLs(entries, cached []cache.CacheEntry) []cache.CacheEntry {
+	previous := make(map[string]cache.CacheEntry, len(cached))
+	for _, entry := range cached {
+		previous[entry.ID] = entry
+	}
+
+	lookups := 0
+	for i, entry := range entries {
+		target := resolveTarget(entry)
+		if target == "" {
+			continue
+		}
+
+		if prev, ok := previous[entry.ID]; ok && prev.ResolvedFrom == target {
+			entries[i].WebURL = prev.WebURL
+			entries[i].ResolvedFrom = target
+			continue
+		}
+
+		if lookups >= maxURLResolutionsPerSync {
+			continue
+		}
+		lookups++
+
+		webURL, err := c.lookupWebURL(entry, target)
+		if err != nil || webURL == "" {
+			logger.Debug().Err(err).Str("url", target).Msg("Failed to resolve notification URL")
+			continue
+		}
+
+		entries[i].WebURL = webURL
+		entries[i].ResolvedFrom = target
+	}
+
+	if lookups > 0 {
+		logger.Debug().Int("lookups", lookups).Msg("Resolved notification URLs")
+	}
+
+	return entries
+}
+
+// lookupWebURL returns the web URL of a resolve target, or an empty string
+// when there is none
+func (c *Client) lookupWebURL(entry cache.CacheEntry, target string) (string, error) {
+	if strings.HasPrefix(target, discussionTargetPrefix) {
+		return c.findDiscussionURL(entry.Repository, entry.Title)
+	}
+
+	var response struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := c.restClient.Get(target, &response); err != nil {
+		return "", err
+	}
+	return response.HTMLURL, nil
+}
+
+// findDiscussionURL returns the URL of the repository discussion with exactly
+// this title, or an empty string when search finds none. Titles are not
+// unique; the best search match wins.
+func (c *Client) findDiscussionURL(repository, title string) (string, error) {
+	search := fmt.Sprintf(`repo:%s in:title "%s"`, repository, strings.ReplaceAll(title, `"`, ""))
+
+	var response struct {
+		Search struct {
+			Nodes []struct {
+				Title string `json:"title"`
+				URL   string `json:"url"`
+			} `json:"nodes"`
+		} `json:"search"`
+	}
+	if err := c.graphqlClient.Do(discussionSearchQuery, map[string]interface{}{"search": search}, &response); err != nil {
+		return "", fmt.Errorf("failed to search discussions: %w", err)
+	}
+
+	for _, node := range response.Search.Nodes {
+		if node.Title == title {
+			return node.URL, nil
+		}
+	}
+	return "", nil
+}
+
+// resolveTarget returns the API URL whose html_url deep-links to the
+// notification, or an empty string when the derived web URL is already exact.
+// Discussions have no API URL, so their target is the title to search for.
+func resolveTarget(entry cache.CacheEntry) string {
+	if entry.LatestCommentURL != "" && entry.LatestCommentURL != entry.URL {
+		return entry.LatestCommentURL
+	}
+
+	if entry.Type == "Discussion" && entry.URL == "" {
+		if entry.Title == "" || entry.Repository == "" {
+			return ""
+		}
+		return discussionTargetPrefix + entry.Title
+	}
+
+	switch entry.Type {
+	case "Issue", "PullRequest", "Commit":
+		// ConvertAPIURLToWeb maps these subjects exactly
+		return ""
+	}
+
+	return entry.URL
+}
+
+// fallbackWebURL links subjects without an API URL, such as discussions and
+// check suites, to the closest repository page. ResolveWebURLs later replaces
+// the discussions page with the discussion itself.
+func fallbackWebURL(subjectType, repoHTMLURL string) string {
+	if repoHTMLURL == "" {
+		return ""
+	}
+
+	switch subjectType {
+	case "Discussion":
+		return repoHTMLURL + "/discussions"
+	case "CheckSuite", "WorkflowRun":
+		return repoHTMLURL + "/actions"
+	case "RepositoryVulnerabilityAlert", "RepositoryDependabotAlertsThread":
+		return repoHTMLURL + "/security/dependabot"
+	}
+
+	return repoHTMLURL
+}
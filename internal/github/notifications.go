@@ -1,25 +1,173 @@
 package github
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"strconv"
 	"time"
 
 	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/logger"
 )
 
+// DefaultPollInterval is used when GitHub does not send an X-Poll-Interval header
+const DefaultPollInterval = 60 * time.Second
+
+const (
+	notificationsPerPage = 50 // Maximum page size allowed by the notifications endpoint
+	maxNotificationPages = 10 // Safety cap: 500 notifications, matching the cache size
+)
+
+// NotificationsResult holds fetched notifications along with the polling
+// metadata GitHub sends in the response headers
+type NotificationsResult struct {
+	Entries      []cache.CacheEntry
+	PollInterval time.Duration // From X-Poll-Interval, or DefaultPollInterval when absent
+	LastModified string        // Last-Modified header to send back on the next poll
+	NotModified  bool          // GitHub answered 304: nothing changed, Entries is empty
+	// Truncated is set when more pages existed beyond maxNotificationPages,
+	// so Entries is not the complete unread set
+	Truncated bool
+}
+
 // FetchNotifications fetches unread GitHub notifications from the REST API.
-// It returns a list of cache entries containing notification details.
+// It returns the cache entries containing notification details together with
+// the poll interval GitHub asks clients to respect.
 // Only unread notifications are fetched (GitHub API default behavior).
-func (c *Client) FetchNotifications() ([]cache.CacheEntry, error) {
-	var response []map[string]interface{}
+//
+// Pages are followed through the Link header up to maxNotificationPages.
+//
+// When lastModified is set it is sent as If-Modified-Since. GitHub then answers
+// 304 Not Modified if nothing changed, which does not count against the rate limit.
+func (c *Client) FetchNotifications(lastModified string) (*NotificationsResult, error) {
+	headers := make(http.Header)
+	if lastModified != "" {
+		headers.Set("If-Modified-Since", lastModified)
+	}
 
 	// Always fetch only unread notifications (GitHub API default)
-	err := c.restClient.Get("notifications", &response)
+	path := fmt.Sprintf("notifications?per_page=%d", notificationsPerPage)
+	result := &NotificationsResult{}
+
+	for page := 0; page < maxNotificationPages; page++ {
+		resp, err := c.restClient.Request(http.MethodGet, path, headers, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch notifications: %w", err)
+		}
+
+		// Polling metadata and the 304 short-circuit only apply to the first page
+		if page == 0 {
+			result.PollInterval = parsePollInterval(resp.Header.Get("X-Poll-Interval"))
+
+			if resp.StatusCode == http.StatusNotModified {
+				_ = resp.Body.Close()
+				result.LastModified = lastModified
+				result.NotModified = true
+				return result, nil
+			}
+
+			result.LastModified = resp.Header.Get("Last-Modified")
+		}
+
+		var response []map[string]interface{}
+		err = json.NewDecoder(resp.Body).Decode(&response)
+		_ = resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode notifications page %d: %w", page+1, err)
+		}
+
+		result.Entries = append(result.Entries, c.parseNotifications(response)...)
+
+		path = parseNextLink(resp.Header.Get("Link"))
+		if path == "" {
+			return result, nil
+		}
+
+		// Later pages are never conditional
+		headers = nil
+	}
+
+	result.Truncated = true
+	logger.Warn().
+		Int("pages", maxNotificationPages).
+		Int("fetched", len(result.Entries)).
+		Msg("Notification list truncated at page limit")
+
+	return result, nil
+}
+
+// MarkThreadRead marks a single notification thread as read
+func (c *Client) MarkThreadRead(threadID string) error {
+	path := fmt.Sprintf("notifications/threads/%s", threadID)
+	if err := c.restClient.Do(http.MethodPatch, path, nil, nil); err != nil {
+		return fmt.Errorf("failed to mark thread %s as read: %w", threadID, err)
+	}
+
+	return nil
+}
+
+// MarkAllRead marks every notification updated at or before lastReadAt as read.
+// When repository (owner/repo) is set, only that repository's notifications are
+// marked. GitHub may process large sets asynchronously and answer 202 Accepted.
+func (c *Client) MarkAllRead(repository string, lastReadAt time.Time) error {
+	path := "notifications"
+	if repository != "" {
+		path = fmt.Sprintf("repos/%s/notifications", repository)
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"last_read_at": lastReadAt.UTC().Format(time.RFC3339),
+		"read":         true,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch notifications: %w", err)
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	if err := c.restClient.Do(http.MethodPut, path, bytes.NewReader(body), nil); err != nil {
+		return fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+
+	return nil
+}
+
+// MuteThread sets the thread subscription to ignored, so GitHub stops sending
+// notifications for it until it is unmuted
+func (c *Client) MuteThread(threadID string) error {
+	if err := c.setThreadIgnored(threadID, true); err != nil {
+		return fmt.Errorf("failed to mute thread %s: %w", threadID, err)
+	}
+
+	return nil
+}
+
+// UnmuteThread subscribes to the thread again. Deleting the subscription
+// instead would leave the thread muted until the next comment or mention.
+func (c *Client) UnmuteThread(threadID string) error {
+	if err := c.setThreadIgnored(threadID, false); err != nil {
+		return fmt.Errorf("failed to unmute thread %s: %w", threadID, err)
+	}
+
+	return nil
+}
+
+// setThreadIgnored sets the ignored flag of the thread subscription
+func (c *Client) setThreadIgnored(threadID string, ignored bool) error {
+	path := fmt.Sprintf("notifications/threads/%s/subscription", threadID)
+	body := bytes.NewReader([]byte(fmt.Sprintf(`{"ignored":%t}`, ignored)))
+	return c.restClient.Do(http.MethodPut, path, body, nil)
+}
+
+// parsePollInterval converts the X-Poll-Interval header (seconds) to a duration.
+// Missing or malformed values fall back to DefaultPollInterval.
+func parsePollInterval(header string) time.Duration {
+	seconds, err := strconv.Atoi(header)
+	if err != nil || seconds <= 0 {
+		return DefaultPollInterval
 	}
 
-	return c.parseNotifications(response), nil
+	return time.Duration(seconds) * time.Second
 }
 
 // parseNotifications converts raw GitHub API notification responses to cache entries
@@ -34,13 +182,16 @@ func (c *Client) parseNotifications(response []map[string]interface{}) []cache.C
 			continue
 		}
 
-		var repository, title, reason, notifType, apiURL string
+		var repository, repoHTMLURL, title, reason, notifType, apiURL, latestCommentURL string
 		var updatedAt time.Time
 
 		if repo, ok := notification["repository"].(map[string]interface{}); ok {
 			if fullName, ok := repo["full_name"].(string); ok {
 				repository = fullName
 			}
+			if htmlURL, ok := repo["html_url"].(string); ok {
+				repoHTMLURL = htmlURL
+			}
 		}
 
 		if subject, ok := notification["subject"].(map[string]interface{}); ok {
@@ -53,6 +204,9 @@ func (c *Client) parseNotifications(response []map[string]interface{}) []cache.C
 			if subjectURL, ok := subject["url"].(string); ok {
 				apiURL = subjectURL
 			}
+			if commentURL, ok := subject["latest_comment_url"].(string); ok {
+				latestCommentURL = commentURL
+			}
 		}
 
 		if reasonStr, ok := notification["reason"].(string); ok {
@@ -63,19 +217,23 @@ func (c *Client) parseNotifications(response []map[string]interface{}) []cache.C
 			updatedAt = parseTime(updatedAtStr)
 		}
 
-		// Convert API URL to web URL
+		// Convert API URL to web URL; ResolveWebURLs refines it later
 		webURL := ConvertAPIURLToWeb(apiURL)
+		if apiURL == "" {
+			webURL = fallbackWebURL(notifType, repoHTMLURL)
+		}
 
 		entry := cache.CacheEntry{
-			ID:         id,
-			Repository: repository,
-			Title:      title,
-			Reason:     reason,
-			Type:       notifType,
-			URL:        apiURL,
-			WebURL:     webURL,
-			Timestamp:  now,
-			UpdatedAt:  updatedAt,
+			ID:               id,
+			Repository:       repository,
+			Title:            title,
+			Reason:           reason,
+			Type:             notifType,
+			URL:              apiURL,
+			WebURL:           webURL,
+			Timestamp:        now,
+			UpdatedAt:        updatedAt,
+			LatestCommentURL: latestCommentURL,
 		}
 
 		entries = append(entries, entry)
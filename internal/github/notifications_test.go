@@ -0,0 +1,613 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/github/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+const testNotificationsJSON = `[
+	{
+		"id": "1001",
+		"reason": "review_requested",
+		"updated_at": "2025-01-01T10:00:00Z",
+		"repository": {"full_name": "owner/repo"},
+		"subject": {
+			"title": "Add feature",
+			"type": "PullRequest",
+			"url": "https://api.github.com/repos/owner/repo/pulls/42"
+		}
+	}
+]`
+
+// newJSONResponse builds an HTTP response with a JSON body and headers
+func newJSONResponse(status int, body string, headers map[string]string) *http.Response {
+	resp := &http.Response{
+		StatusCode: status,
+		Header:     make(http.Header),
+		Body:       io.NopCloser(strings.NewReader(body)),
+	}
+	for key, value := range headers {
+		resp.Header.Set(key, value)
+	}
+	return resp
+}
+
+// TestFetchNotifications_PollInterval tests parsing entries and the X-Poll-Interval header
+func TestFetchNotifications_PollInterval(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	mockREST.EXPECT().
+		Request(http.MethodGet, "notifications?per_page=50", gomock.Any(), gomock.Nil()).
+		Return(newJSONResponse(http.StatusOK, testNotificationsJSON, map[string]string{
+			"X-Poll-Interval": "120",
+			"Last-Modified":   "Wed, 01 Jan 2025 10:00:00 GMT",
+		}), nil).
+		Times(1)
+
+	result, err := client.FetchNotifications("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.PollInterval != 120*time.Second {
+		t.Errorf("Expected poll interval 120s, got %v", result.PollInterval)
+	}
+
+	if result.LastModified != "Wed, 01 Jan 2025 10:00:00 GMT" {
+		t.Errorf("Expected Last-Modified to be returned, got %q", result.LastModified)
+	}
+
+	if result.NotModified {
+		t.Error("Expected NotModified to be false for a 200 response")
+	}
+
+	if len(result.Entries) != 1 {
+		t.Fatalf("Expected 1 notification, got %d", len(result.Entries))
+	}
+
+	entry := result.Entries[0]
+	if entry.ID != "1001" || entry.Repository != "owner/repo" || entry.Type != "PullRequest" {
+		t.Errorf("Unexpected entry: %+v", entry)
+	}
+
+	if entry.WebURL != "https://github.com/owner/repo/pull/42" {
+		t.Errorf("Expected web URL to be converted, got %q", entry.WebURL)
+	}
+
+	t.Logf("✓ Poll interval test passed!")
+}
+
+// TestFetchNotifications_NotModified tests conditional requests answered with 304
+func TestFetchNotifications_NotModified(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	lastModified := "Wed, 01 Jan 2025 10:00:00 GMT"
+
+	mockREST.EXPECT().
+		Request(http.MethodGet, "notifications?per_page=50", gomock.Any(), gomock.Nil()).
+		DoAndReturn(func(method, path string, headers http.Header, body io.Reader) (*http.Response, error) {
+			if got := headers.Get("If-Modified-Since"); got != lastModified {
+				t.Errorf("Expected If-Modified-Since %q, got %q", lastModified, got)
+			}
+			return newJSONResponse(http.StatusNotModified, "", map[string]string{
+				"X-Poll-Interval": "60",
+			}), nil
+		}).
+		Times(1)
+
+	result, err := client.FetchNotifications(lastModified)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !result.NotModified {
+		t.Error("Expected NotModified to be true for a 304 response")
+	}
+
+	if len(result.Entries) != 0 {
+		t.Errorf("Expected no entries for a 304 response, got %d", len(result.Entries))
+	}
+
+	if result.LastModified != lastModified {
+		t.Errorf("Expected Last-Modified to be kept, got %q", result.LastModified)
+	}
+
+	t.Logf("✓ Not modified test passed!")
+}
+
+// TestFetchNotifications_Error tests that request errors are returned
+func TestFetchNotifications_Error(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	mockREST.EXPECT().
+		Request(http.MethodGet, "notifications?per_page=50", gomock.Any(), gomock.Nil()).
+		Return(nil, fmt.Errorf("HTTP 502")).
+		Times(1)
+
+	result, err := client.FetchNotifications("")
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	if result != nil {
+		t.Errorf("Expected nil result on error, got %+v", result)
+	}
+
+	t.Logf("✓ Fetch error test passed!")
+}
+
+// TestFetchNotifications_Pagination tests following Link headers across pages
+func TestFetchNotifications_Pagination(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	page2 := "https://api.github.com/notifications?per_page=50&page=2"
+
+	gomock.InOrder(
+		mockREST.EXPECT().
+			Request(http.MethodGet, "notifications?per_page=50", gomock.Any(), gomock.Nil()).
+			Return(newJSONResponse(http.StatusOK, testNotificationsJSON, map[string]string{
+				"Last-Modified": "Wed, 01 Jan 2025 10:00:00 GMT",
+				"Link":          `<` + page2 + `>; rel="next", <https://api.github.com/notifications?per_page=50&page=2>; rel="last"`,
+			}), nil),
+		mockREST.EXPECT().
+			Request(http.MethodGet, page2, gomock.Nil(), gomock.Nil()).
+			Return(newJSONResponse(http.StatusOK, strings.ReplaceAll(testNotificationsJSON, "1001", "1002"), nil), nil),
+	)
+
+	result, err := client.FetchNotifications("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(result.Entries) != 2 {
+		t.Fatalf("Expected 2 notifications across pages, got %d", len(result.Entries))
+	}
+
+	if result.Entries[1].ID != "1002" {
+		t.Errorf("Expected second page entry '1002', got '%s'", result.Entries[1].ID)
+	}
+
+	if result.Truncated {
+		t.Error("Expected complete result not to be truncated")
+	}
+
+	if result.LastModified != "Wed, 01 Jan 2025 10:00:00 GMT" {
+		t.Errorf("Expected Last-Modified from the first page, got %q", result.LastModified)
+	}
+
+	t.Logf("✓ Pagination test passed!")
+}
+
+// TestFetchNotifications_Truncated tests the page safety cap
+func TestFetchNotifications_Truncated(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	// Every page claims there is another one
+	mockREST.EXPECT().
+		Request(http.MethodGet, gomock.Any(), gomock.Any(), gomock.Nil()).
+		DoAndReturn(func(method, path string, headers http.Header, body io.Reader) (*http.Response, error) {
+			return newJSONResponse(http.StatusOK, testNotificationsJSON, map[string]string{
+				"Link": `<https://api.github.com/notifications?page=next>; rel="next"`,
+			}), nil
+		}).
+		Times(maxNotificationPages)
+
+	result, err := client.FetchNotifications("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if !result.Truncated {
+		t.Error("Expected result to be truncated at the page limit")
+	}
+
+	if len(result.Entries) != maxNotificationPages {
+		t.Errorf("Expected %d entries, got %d", maxNotificationPages, len(result.Entries))
+	}
+
+	t.Logf("✓ Truncation test passed!")
+}
+
+// TestParseNextLink tests extracting the next page URL from Link headers
+func TestParseNextLink(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{
+			name:     "next and last",
+			header:   `<https://api.github.com/notifications?page=2>; rel="next", <https://api.github.com/notifications?page=5>; rel="last"`,
+			expected: "https://api.github.com/notifications?page=2",
+		},
+		{
+			name:     "next not first",
+			header:   `<https://api.github.com/notifications?page=1>; rel="prev", <https://api.github.com/notifications?page=3>; rel="next"`,
+			expected: "https://api.github.com/notifications?page=3",
+		},
+		{
+			name:     "last page",
+			header:   `<https://api.github.com/notifications?page=1>; rel="first", <https://api.github.com/notifications?page=4>; rel="prev"`,
+			expected: "",
+		},
+		{
+			name:     "empty",
+			header:   "",
+			expected: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := parseNextLink(tc.header); got != tc.expected {
+				t.Errorf("parseNextLink(%q) = %q, want %q", tc.header, got, tc.expected)
+			}
+		})
+	}
+
+	t.Logf("✓ Link parsing test passed!")
+}
+
+// TestParsePollInterval tests header parsing and fallbacks
+func TestParsePollInterval(t *testing.T) {
+	testCases := []struct {
+		header   string
+		expected time.Duration
+	}{
+		{"60", 60 * time.Second},
+		{"300", 300 * time.Second},
+		{"", DefaultPollInterval},
+		{"soon", DefaultPollInterval},
+		{"0", DefaultPollInterval},
+		{"-5", DefaultPollInterval},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.header, func(t *testing.T) {
+			if got := parsePollInterval(tc.header); got != tc.expected {
+				t.Errorf("parsePollInterval(%q) = %v, want %v", tc.header, got, tc.expected)
+			}
+		})
+	}
+
+	t.Logf("✓ Poll interval parsing test passed!")
+}
+
+// TestMarkThreadRead tests marking a thread as read and surfacing errors
+func TestMarkThreadRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	gomock.InOrder(
+		mockREST.EXPECT().
+			Do(http.MethodPatch, "notifications/threads/123", gomock.Nil(), gomock.Nil()).
+			Return(nil),
+		mockREST.EXPECT().
+			Do(http.MethodPatch, "notifications/threads/456", gomock.Nil(), gomock.Nil()).
+			Return(fmt.Errorf("HTTP 404")),
+	)
+
+	if err := client.MarkThreadRead("123"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := client.MarkThreadRead("456"); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+
+	t.Logf("✓ Mark thread read test passed!")
+}
+
+// TestMarkAllRead tests the bulk endpoints and the last_read_at body
+func TestMarkAllRead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	lastReadAt := time.Date(2025, 1, 1, 10, 0, 0, 0, time.UTC)
+
+	checkBody := func(method, path string, body io.Reader, response interface{}) error {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(body).Decode(&payload); err != nil {
+			t.Fatalf("Expected JSON body, got error %v", err)
+		}
+		if payload["last_read_at"] != "2025-01-01T10:00:00Z" || payload["read"] != true {
+			t.Errorf("Unexpected body: %+v", payload)
+		}
+		return nil
+	}
+
+	gomock.InOrder(
+		mockREST.EXPECT().
+			Do(http.MethodPut, "notifications", gomock.Any(), gomock.Nil()).
+			DoAndReturn(checkBody),
+		mockREST.EXPECT().
+			Do(http.MethodPut, "repos/owner/repo/notifications", gomock.Any(), gomock.Nil()).
+			DoAndReturn(checkBody),
+	)
+
+	if err := client.MarkAllRead("", lastReadAt); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if err := client.MarkAllRead("owner/repo", lastReadAt); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	t.Logf("✓ Mark all read test passed!")
+}
+
+// TestMuteThread tests muting and unmuting thread subscriptions
+func TestMuteThread(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	// Both set the subscription, as deleting it would not undo ignored
+	expectIgnored := func(ignored bool) func(string, string, io.Reader, interface{}) error {
+		return func(method, path string, body io.Reader, response interface{}) error {
+			var payload map[string]interface{}
+			if err := json.NewDecoder(body).Decode(&payload); err != nil {
+				t.Fatalf("Expected JSON body, got error %v", err)
+			}
+			if payload["ignored"] != ignored {
+				t.Errorf("Expected ignored to be %t, got %+v", ignored, payload)
+			}
+			return nil
+		}
+	}
+	gomock.InOrder(
+		mockREST.EXPECT().
+			Do(http.MethodPut, "notifications/threads/123/subscription", gomock.Any(), gomock.Nil()).
+			DoAndReturn(expectIgnored(true)),
+		mockREST.EXPECT().
+			Do(http.MethodPut, "notifications/threads/123/subscription", gomock.Any(), gomock.Nil()).
+			DoAndReturn(expectIgnored(false)),
+	)
+
+	if err := client.MuteThread("123"); err != nil {
+		t.Fatalf("Expected no error muting, got %v", err)
+	}
+
+	if err := client.UnmuteThread("123"); err != nil {
+		t.Fatalf("Expected no error unmuting, got %v", err)
+	}
+
+	t.Logf("✓ Mute thread test passed!")
+}
+
+// TestResolveWebURLs tests deep links from comments and releases, and reuse of cached results
+func TestResolveWebURLs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	commentURL := "https://api.github.com/repos/owner/repo/issues/comments/777"
+	releaseURL := "https://api.github.com/repos/owner/repo/releases/555"
+	cachedURL := "https://api.github.com/repos/owner/repo/issues/comments/888"
+
+	entries := []cache.CacheEntry{
+		{
+			ID:               "1",
+			Type:             "Issue",
+			URL:              "https://api.github.com/repos/owner/repo/issues/1",
+			WebURL:           "https://github.com/owner/repo/issues/1",
+			LatestCommentURL: commentURL,
+		},
+		{
+			ID:               "2",
+			Type:             "Release",
+			URL:              releaseURL,
+			WebURL:           "https://github.com/owner/repo/releases",
+			LatestCommentURL: releaseURL,
+		},
+		{
+			// Exact without a lookup
+			ID:               "3",
+			Type:             "PullRequest",
+			URL:              "https://api.github.com/repos/owner/repo/pulls/3",
+			WebURL:           "https://github.com/owner/repo/pull/3",
+			LatestCommentURL: "https://api.github.com/repos/owner/repo/pulls/3",
+		},
+		{
+			// Resolved on a previous sync
+			ID:               "4",
+			Type:             "Issue",
+			URL:              "https://api.github.com/repos/owner/repo/issues/4",
+			WebURL:           "https://github.com/owner/repo/issues/4",
+			LatestCommentURL: cachedURL,
+		},
+	}
+	cached := []cache.CacheEntry{
+		{ID: "4", WebURL: "https://github.com/owner/repo/issues/4#issuecomment-888", ResolvedFrom: cachedURL},
+	}
+
+	mockREST.EXPECT().
+		Get(commentURL, gomock.Any()).
+		DoAndReturn(func(path string, response interface{}) error {
+			return json.Unmarshal([]byte(`{"html_url": "https://github.com/owner/repo/issues/1#issuecomment-777"}`), response)
+		})
+	mockREST.EXPECT().
+		Get(releaseURL, gomock.Any()).
+		DoAndReturn(func(path string, response interface{}) error {
+			return json.Unmarshal([]byte(`{"html_url": "https://github.com/owner/repo/releases/tag/v1.2.0"}`), response)
+		})
+
+	result := client.ResolveWebURLs(entries, cached)
+
+	expected := []string{
+		"https://github.com/owner/repo/issues/1#issuecomment-777",
+		"https://github.com/owner/repo/releases/tag/v1.2.0",
+		"https://github.com/owner/repo/pull/3",
+		"https://github.com/owner/repo/issues/4#issuecomment-888",
+	}
+	for i, url := range expected {
+		if result[i].WebURL != url {
+			t.Errorf("Entry %s: expected %q, got %q", result[i].ID, url, result[i].WebURL)
+		}
+	}
+
+	if result[0].ResolvedFrom != commentURL {
+		t.Errorf("Expected ResolvedFrom to record the comment URL, got %q", result[0].ResolvedFrom)
+	}
+
+	t.Logf("✓ Resolve web URLs test passed!")
+}
+
+// TestResolveWebURLs_ErrorKeepsDerivedURL tests that failed lookups keep the derived URL
+func TestResolveWebURLs_ErrorKeepsDerivedURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	releaseURL := "https://api.github.com/repos/owner/repo/releases/555"
+	entries := []cache.CacheEntry{
+		{ID: "1", Type: "Release", URL: releaseURL, WebURL: "https://github.com/owner/repo/releases"},
+	}
+
+	mockREST.EXPECT().Get(releaseURL, gomock.Any()).Return(fmt.Errorf("HTTP 404"))
+
+	result := client.ResolveWebURLs(entries, nil)
+
+	if result[0].WebURL != "https://github.com/owner/repo/releases" || result[0].ResolvedFrom != "" {
+		t.Errorf("Expected derived URL to be kept unresolved, got %+v", result[0])
+	}
+
+	t.Logf("✓ Resolve error fallback test passed!")
+}
+
+// TestResolveWebURLs_Discussion tests finding a discussion by its title, as
+// discussion notifications carry no subject URL
+func TestResolveWebURLs_Discussion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	entries := []cache.CacheEntry{
+		{ID: "1", Type: "Discussion", Repository: "owner/repo", Title: "Ideas", WebURL: "https://github.com/owner/repo/discussions"},
+	}
+
+	mockGraphQL.EXPECT().
+		Do(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(query string, variables map[string]interface{}, response interface{}) error {
+			if search := variables["search"]; search != `repo:owner/repo in:title "Ideas"` {
+				t.Errorf("Unexpected search %q", search)
+			}
+			// Search also matches longer titles, which must be skipped
+			return json.Unmarshal([]byte(`{"search": {"nodes": [
+				{"title": "More ideas", "url": "https://github.com/owner/repo/discussions/3"},
+				{"title": "Ideas", "url": "https://github.com/owner/repo/discussions/7"}
+			]}}`), response)
+		})
+
+	result := client.ResolveWebURLs(entries, nil)
+	if result[0].WebURL != "https://github.com/owner/repo/discussions/7" || result[0].ResolvedFrom != "discussion:Ideas" {
+		t.Errorf("Expected the discussion link, got %+v", result[0])
+	}
+
+	// The next sync reuses the link without searching again
+	again := []cache.CacheEntry{
+		{ID: "1", Type: "Discussion", Repository: "owner/repo", Title: "Ideas", WebURL: "https://github.com/owner/repo/discussions"},
+	}
+	if result := client.ResolveWebURLs(again, result); result[0].WebURL != "https://github.com/owner/repo/discussions/7" {
+		t.Errorf("Expected the cached discussion link, got %q", result[0].WebURL)
+	}
+
+	t.Logf("✓ Resolve discussion test passed!")
+}
+
+// TestFetchNotifications_SubjectWithoutURL tests fallback links for discussions and check suites
+func TestFetchNotifications_SubjectWithoutURL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	body := `[
+		{
+			"id": "2001",
+			"reason": "subscribed",
+			"repository": {"full_name": "owner/repo", "html_url": "https://github.com/owner/repo"},
+			"subject": {"title": "Ideas", "type": "Discussion", "url": null, "latest_comment_url": null}
+		},
+		{
+			"id": "2002",
+			"reason": "ci_activity",
+			"repository": {"full_name": "owner/repo", "html_url": "https://github.com/owner/repo"},
+			"subject": {"title": "CI failed", "type": "CheckSuite", "url": null}
+		}
+	]`
+
+	mockREST.EXPECT().
+		Request(http.MethodGet, "notifications?per_page=50", gomock.Any(), gomock.Nil()).
+		Return(newJSONResponse(http.StatusOK, body, nil), nil)
+
+	result, err := client.FetchNotifications("")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.Entries[0].WebURL != "https://github.com/owner/repo/discussions" {
+		t.Errorf("Expected discussions link, got %q", result.Entries[0].WebURL)
+	}
+	if result.Entries[1].WebURL != "https://github.com/owner/repo/actions" {
+		t.Errorf("Expected actions link, got %q", result.Entries[1].WebURL)
+	}
+
+	t.Logf("✓ Subject without URL test passed!")
+}
@@ -0,0 +1,133 @@
+package github
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/logger"
+)
+
+// maxSubjectsPerQuery bounds the issues and pull requests looked up by the
+// single GraphQL query EnrichSubjects sends; the rest keep their cached state
+const maxSubjectsPerQuery = 100
+
+// subjectNumberRegex extracts the issue or pull request number from a subject API URL
+var subjectNumberRegex = regexp.MustCompile(`/(?:issues|pulls)/(\d+)$`)
+
+// subjectNode is the issueOrPullRequest part of the batched subject query
+type subjectNode struct {
+	State          string `json:"state"`
+	IsDraft        bool   `json:"isDraft"`
+	ReviewDecision string `json:"reviewDecision"`
+	Commits        struct {
+		Nodes []struct {
+			Commit struct {
+				StatusCheckRollup *struct {
+					State string `json:"state"`
+				} `json:"statusCheckRollup"`
+			} `json:"commit"`
+		} `json:"nodes"`
+	} `json:"commits"`
+}
+
+// subjectFields is selected for every aliased repository in the batched query
+const subjectFields = `
+			issueOrPullRequest(number: $number%[1]d) {
+				... on Issue { state }
+				... on PullRequest {
+					state
+					isDraft
+					reviewDecision
+					commits(last: 1) { nodes { commit { statusCheckRollup { state } } } }
+				}
+			}`
+
+// EnrichSubjects sets the state, draft flag, review decision and CI status of
+// issue and pull request notifications, fetched with one batched GraphQL query.
+// Entries start from their state in cached, so a failed or partial query keeps
+// the last known state rather than clearing it.
+func (c *Client) EnrichSubjects(entries, cached []cache.CacheEntry) []cache.CacheEntry {
+	previous := make(map[string]*cache.SubjectState, len(cached))
+	for _, entry := range cached {
+		previous[entry.ID] = entry.Subject
+	}
+
+	var params []string
+	var selections []string
+	variables := map[string]interface{}{}
+	aliases := map[string]int{}
+
+	for i, entry := range entries {
+		entries[i].Subject = previous[entry.ID]
+
+		if entry.Type != "Issue" && entry.Type != "PullRequest" {
+			continue
+		}
+		matches := subjectNumberRegex.FindStringSubmatch(entry.URL)
+		if matches == nil || len(aliases) >= maxSubjectsPerQuery {
+			continue
+		}
+		number, err := strconv.Atoi(matches[1])
+		if err != nil {
+			continue
+		}
+
+		n := len(aliases)
+		alias := fmt.Sprintf("s%d", n)
+		aliases[alias] = i
+		params = append(params, fmt.Sprintf("$owner%[1]d: String!, $name%[1]d: String!, $number%[1]d: Int!", n))
+		selections = append(selections, fmt.Sprintf("\t\t%s: repository(owner: $owner%d, name: $name%d) {", alias, n, n)+
+			fmt.Sprintf(subjectFields, n)+"\n\t\t}")
+		variables[fmt.Sprintf("owner%d", n)] = GetOwner(entry.Repository)
+		variables[fmt.Sprintf("name%d", n)] = GetName(entry.Repository)
+		variables[fmt.Sprintf("number%d", n)] = number
+	}
+
+	if len(aliases) == 0 {
+		return entries
+	}
+
+	query := fmt.Sprintf("query(%s) {\n%s\n\t}", strings.Join(params, ", "), strings.Join(selections, "\n"))
+
+	// Subjects that no longer exist or are not visible come back null with an
+	// error while the others are still decoded, so a failure is not fatal.
+	// Such subjects fail on every sync, so this only logs at debug level.
+	response := map[string]*struct {
+		IssueOrPullRequest *subjectNode `json:"issueOrPullRequest"`
+	}{}
+	if err := c.graphqlClient.Do(query, variables, &response); err != nil {
+		logger.Debug().Err(err).Int("subjects", len(aliases)).Msg("Failed to fetch some notification subject states")
+	}
+
+	for alias, i := range aliases {
+		repo := response[alias]
+		if repo == nil || repo.IssueOrPullRequest == nil {
+			continue
+		}
+		entries[i].Subject = repo.IssueOrPullRequest.subjectState()
+	}
+
+	logger.Debug().Int("subjects", len(aliases)).Msg("Fetched notification subject states")
+
+	return entries
+}
+
+// subjectState converts a GraphQL subject node to its cached form
+func (n *subjectNode) subjectState() *cache.SubjectState {
+	state := &cache.SubjectState{
+		State:          n.State,
+		Draft:          n.IsDraft,
+		ReviewDecision: n.ReviewDecision,
+	}
+
+	if len(n.Commits.Nodes) > 0 {
+		if rollup := n.Commits.Nodes[0].Commit.StatusCheckRollup; rollup != nil {
+			state.CheckStatus = rollup.State
+		}
+	}
+
+	return state
+}
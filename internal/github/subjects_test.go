@@ -0,0 +1,108 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/github/mocks"
+	"go.uber.org/mock/gomock"
+)
+
+// TestEnrichSubjects tests that issue and pull request states come from one batched query
+func TestEnrichSubjects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	entries := []cache.CacheEntry{
+		{ID: "1", Type: "PullRequest", Repository: "owner/repo", URL: "https://api.github.com/repos/owner/repo/pulls/12"},
+		{ID: "2", Type: "Issue", Repository: "other/tool", URL: "https://api.github.com/repos/other/tool/issues/3"},
+		{ID: "3", Type: "Release", Repository: "owner/repo", URL: "https://api.github.com/repos/owner/repo/releases/9"},
+	}
+
+	mockGraphQL.EXPECT().
+		Do(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(query string, variables map[string]interface{}, response interface{}) error {
+			if !strings.Contains(query, "s0: repository(owner: $owner0, name: $name0)") || strings.Contains(query, "s2:") {
+				t.Errorf("Unexpected query aliases:\n%s", query)
+			}
+			if variables["owner1"] != "other" || variables["name1"] != "tool" || variables["number1"] != 3 {
+				t.Errorf("Unexpected variables: %v", variables)
+			}
+
+			return json.Unmarshal([]byte(`{
+				"s0": {"issueOrPullRequest": {
+					"state": "OPEN", "isDraft": false, "reviewDecision": "APPROVED",
+					"commits": {"nodes": [{"commit": {"statusCheckRollup": {"state": "FAILURE"}}}]}
+				}},
+				"s1": {"issueOrPullRequest": {"state": "CLOSED"}}
+			}`), response)
+		})
+
+	result := client.EnrichSubjects(entries, nil)
+
+	expected := cache.SubjectState{State: "OPEN", ReviewDecision: "APPROVED", CheckStatus: "FAILURE"}
+	if result[0].Subject == nil || *result[0].Subject != expected {
+		t.Errorf("Expected pull request state %+v, got %+v", expected, result[0].Subject)
+	}
+	if result[1].Subject == nil || result[1].Subject.State != "CLOSED" {
+		t.Errorf("Expected closed issue, got %+v", result[1].Subject)
+	}
+	if result[2].Subject != nil {
+		t.Errorf("Expected no state for a release, got %+v", result[2].Subject)
+	}
+
+	t.Logf("✓ Enrich subjects test passed!")
+}
+
+// TestEnrichSubjects_ErrorKeepsCachedState tests that a failed query keeps the last known state
+func TestEnrichSubjects_ErrorKeepsCachedState(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	entries := []cache.CacheEntry{
+		{ID: "1", Type: "PullRequest", Repository: "owner/repo", URL: "https://api.github.com/repos/owner/repo/pulls/12"},
+	}
+	cached := []cache.CacheEntry{
+		{ID: "1", Subject: &cache.SubjectState{State: "MERGED"}},
+	}
+
+	mockGraphQL.EXPECT().
+		Do(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(fmt.Errorf("Could not resolve to a Repository"))
+
+	result := client.EnrichSubjects(entries, cached)
+
+	if result[0].Subject == nil || result[0].Subject.State != "MERGED" {
+		t.Errorf("Expected cached state to be kept, got %+v", result[0].Subject)
+	}
+
+	t.Logf("✓ Enrich subjects error fallback test passed!")
+}
+
+// TestEnrichSubjects_NoSubjects tests that no query is sent without issues or pull requests
+func TestEnrichSubjects_NoSubjects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockGraphQL := mocks.NewMockGraphQLClient(ctrl)
+	mockREST := mocks.NewMockRESTClient(ctrl)
+	client := NewTestClient(mockREST, mockGraphQL)
+
+	entries := []cache.CacheEntry{{ID: "1", Type: "Discussion", Repository: "owner/repo"}}
+
+	// No EXPECT: any GraphQL call fails the test
+	client.EnrichSubjects(entries, nil)
+
+	t.Logf("✓ Enrich subjects without subjects test passed!")
+}
@@ -26,50 +26,94 @@ func GetName(repoFullName string) string {
 	return ""
 }
 
+// parseNextLink returns the rel="next" URL from a Link pagination header,
+// or an empty string when there is no next page.
+// Example: <https://api.github.com/notifications?page=2>; rel="next", <...>; rel="last"
+func parseNextLink(header string) string {
+	for _, link := range strings.Split(header, ",") {
+		parts := strings.Split(link, ";")
+		if len(parts) < 2 {
+			continue
+		}
+
+		for _, param := range parts[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(parts[0]), "<>")
+			}
+		}
+	}
+
+	return ""
+}
+
+// apiURLRegex splits an API URL into its host and path. It matches both
+// github.com style hosts (https://api.github.com/...) and GitHub Enterprise
+// Server (https://ghe.example.com/api/v3/...).
+var apiURLRegex = regexp.MustCompile(`^https://(?:api\.([^/]+)|([^/]+)/api/v3)(/.*)$`)
+
 // ConvertAPIURLToWeb converts GitHub API URLs to web URLs.
 // Example: https://api.github.com/repos/owner/repo/issues/123 -> https://github.com/owner/repo/issues/123
+// Example: https://ghe.example.com/api/v3/repos/owner/repo/pulls/1 -> https://ghe.example.com/owner/repo/pull/1
 func ConvertAPIURLToWeb(apiURL string) string {
 	if apiURL == "" {
 		return ""
 	}
 
-	// Regular expression to match different GitHub API URL patterns
+	matches := apiURLRegex.FindStringSubmatch(apiURL)
+	if matches == nil {
+		// If we can't convert, return the original URL
+		return apiURL
+	}
+
+	host := matches[1]
+	if host == "" {
+		host = matches[2]
+	}
+	webRoot := "https://" + host
+	path := matches[3]
+
+	// Regular expression to match different GitHub API URL paths
 	patterns := []struct {
 		regex       *regexp.Regexp
 		replacement string
 	}{
-		// Issues: https://api.github.com/repos/owner/repo/issues/123
+		// Issues: /repos/owner/repo/issues/123
+		{
+			regex:       regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/issues/(\d+)$`),
+			replacement: "/$1/$2/issues/$3",
+		},
+		// Pull requests: /repos/owner/repo/pulls/123
 		{
-			regex:       regexp.MustCompile(`^https://api\.github\.com/repos/([^/]+)/([^/]+)/issues/(\d+)$`),
-			replacement: "https://github.com/$1/$2/issues/$3",
+			regex:       regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/pulls/(\d+)$`),
+			replacement: "/$1/$2/pull/$3",
 		},
-		// Pull requests: https://api.github.com/repos/owner/repo/pulls/123
+		// Commits: /repos/owner/repo/commits/sha
 		{
-			regex:       regexp.MustCompile(`^https://api\.github\.com/repos/([^/]+)/([^/]+)/pulls/(\d+)$`),
-			replacement: "https://github.com/$1/$2/pull/$3",
+			regex:       regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/commits/([0-9a-f]+)$`),
+			replacement: "/$1/$2/commit/$3",
 		},
-		// Releases: https://api.github.com/repos/owner/repo/releases/123
+		// Releases: /repos/owner/repo/releases/123 (the tag name needs a lookup)
 		{
-			regex:       regexp.MustCompile(`^https://api\.github\.com/repos/([^/]+)/([^/]+)/releases/(\d+)$`),
-			replacement: "https://github.com/$1/$2/releases/tag/$3", // Note: this might need release tag name
+			regex:       regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/releases/(\d+)$`),
+			replacement: "/$1/$2/releases",
 		},
-		// Comments: https://api.github.com/repos/owner/repo/issues/comments/123
+		// Comments: /repos/owner/repo/issues/comments/123
 		{
-			regex:       regexp.MustCompile(`^https://api\.github\.com/repos/([^/]+)/([^/]+)/issues/comments/(\d+)$`),
-			replacement: "https://github.com/$1/$2/issues", // Will redirect to the issue
+			regex:       regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/issues/comments/(\d+)$`),
+			replacement: "/$1/$2/issues", // Will redirect to the issue
 		},
 	}
 
 	for _, pattern := range patterns {
-		if pattern.regex.MatchString(apiURL) {
-			return pattern.regex.ReplaceAllString(apiURL, pattern.replacement)
+		if pattern.regex.MatchString(path) {
+			return webRoot + pattern.regex.ReplaceAllString(path, pattern.replacement)
 		}
 	}
 
 	// Fallback: try to extract owner/repo and create a general repo URL
-	repoRegex := regexp.MustCompile(`^https://api\.github\.com/repos/([^/]+)/([^/]+)/`)
-	if matches := repoRegex.FindStringSubmatch(apiURL); len(matches) >= 3 {
-		return fmt.Sprintf("https://github.com/%s/%s", matches[1], matches[2])
+	repoRegex := regexp.MustCompile(`^/repos/([^/]+)/([^/]+)/`)
+	if matches := repoRegex.FindStringSubmatch(path); len(matches) >= 3 {
+		return fmt.Sprintf("%s/%s/%s", webRoot, matches[1], matches[2])
 	}
 
 	// If we can't convert, return the original URL
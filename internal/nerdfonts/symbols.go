@@ -7,6 +7,11 @@ const (
 	Release      = "\uf135" //
 	Repository   = "\uf07c" //
 	DefaultNotif = "\uf0f6" //
+	Discussion   = "\uf086" // nf-fa-comments
+	CheckSuite   = "\uf085" // nf-fa-cogs
+	WorkflowRun  = "\uf04b" // nf-fa-play
+	Security     = "\uf132" // nf-fa-shield
+	Invitation   = "\uf0e0" // nf-fa-envelope
 )
 
 // Reason/action symbols
@@ -17,6 +22,10 @@ const (
 	Author          = "\uf040" //
 	StateChange     = "\uf021" //
 	Commit          = "\uf417" //
+	Approval        = "\uf00c" // nf-fa-check
+	TeamMention     = "\uf0c0" // nf-fa-users
+	Credit          = "\uf091" // nf-fa-trophy
+	FeatureRequest  = "\uf0eb" // nf-fa-lightbulb_o
 )
 
 // Status symbols
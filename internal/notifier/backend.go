@@ -0,0 +1,99 @@
+package notifier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/logger"
+)
+
+// Backend names accepted by NewBackend
+const (
+	BackendAuto       = "auto"
+	BackendDBus       = "dbus"
+	BackendNotifySend = "notify-send"
+)
+
+// Backend displays desktop notifications
+type Backend interface {
+	// Show displays n and, when it has actions, waits until one is invoked or
+	// the notification is closed
+	Show(n Notification) (Result, error)
+	// CloseNotification removes the notification with the given ID
+	CloseNotification(id uint32) error
+	// Close releases the backend's resources
+	Close() error
+}
+
+// Notification is a desktop notification as sent to a Backend
+type Notification struct {
+	AppName    string   `json:"app_name"`
+	ReplacesID uint32   `json:"replaces_id,omitempty"` // Replace this earlier notification instead of adding one; 0 adds one
+	Title      string   `json:"title"`
+	Body       string   `json:"body"`
+	Urgency    string   `json:"urgency"`           // low, normal or critical
+	Actions    []Action `json:"actions,omitempty"` // The "default" action runs when the body is clicked
+	// WaitTimeout bounds how long Show waits for an action; 0 waits until the
+	// notification is closed. The server still expires it as it sees fit.
+	WaitTimeout time.Duration `json:"wait_timeout,omitempty"`
+	// Persistent notifications never expire and ask the server to keep them
+	// (the resident hint) until they are dismissed or closed
+	Persistent bool `json:"persistent,omitempty"`
+	// Extra hints, e.g. "category": "im.received". JSON decoding turns
+	// numbers into float64, so only string and bool hints survive a Pending.
+	Hints map[string]interface{} `json:"hints,omitempty"`
+}
+
+// expireTimeout returns the expire timeout servers expect: 0 never expires
+// and -1 leaves it to the server
+func (n Notification) expireTimeout() int32 {
+	if n.Persistent {
+		return 0
+	}
+	return -1
+}
+
+// Action is a notification button as shown by a Backend
+type Action struct {
+	Key   string `json:"key"`
+	Label string `json:"label"`
+}
+
+// CloseReason tells why a notification was closed, as defined by the
+// Desktop Notifications Specification
+type CloseReason uint32
+
+const (
+	CloseExpired   CloseReason = 1 // The notification expired
+	CloseDismissed CloseReason = 2 // The user dismissed it
+	CloseByCall    CloseReason = 3 // CloseNotification was called
+	CloseUndefined CloseReason = 4 // The server did not say
+)
+
+// Result reports what became of a shown notification
+type Result struct {
+	ID          uint32      // Server ID, usable as ReplacesID; 0 when the backend has none
+	Action      string      // Key of the invoked action, empty if none
+	CloseReason CloseReason // Set when the notification was closed without an action
+}
+
+// NewBackend returns the named backend. "auto" (or empty) uses D-Bus when a
+// notification server answers on the session bus and falls back to
+// notify-send otherwise.
+func NewBackend(name string) (Backend, error) {
+	switch name {
+	case BackendNotifySend:
+		return &notifySendBackend{}, nil
+	case BackendDBus:
+		return NewDBusBackend()
+	case "", BackendAuto:
+		backend, err := NewDBusBackend()
+		if err != nil {
+			logger.Debug().Err(err).Msg("D-Bus notifications unavailable, falling back to notify-send")
+			return &notifySendBackend{}, nil
+		}
+		return backend, nil
+	default:
+		return nil, fmt.Errorf("unknown notification backend %q", name)
+	}
+}
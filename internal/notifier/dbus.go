@@ -0,0 +1,158 @@
+package notifier
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+const (
+	dbusName      = "org.freedesktop.Notifications"
+	dbusPath      = dbus.ObjectPath("/org/freedesktop/Notifications")
+	dbusInterface = "org.freedesktop.Notifications"
+)
+
+// dbusBackend talks to the org.freedesktop.Notifications server directly
+type dbusBackend struct {
+	conn *dbus.Conn
+	obj  dbus.BusObject
+}
+
+// NewDBusBackend connects to the session bus and checks that a notification
+// server is running
+func NewDBusBackend() (Backend, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to session bus: %w", err)
+	}
+
+	backend, err := newDBusBackend(conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	return backend, nil
+}
+
+// newDBusBackend uses an open connection, such as one to a private test bus
+func newDBusBackend(conn *dbus.Conn) (*dbusBackend, error) {
+	obj := conn.Object(dbusName, dbusPath)
+
+	var capabilities []string
+	if err := obj.Call(dbusInterface+".GetCapabilities", 0).Store(&capabilities); err != nil {
+		return nil, fmt.Errorf("failed to reach notification server: %w", err)
+	}
+
+	if err := conn.AddMatchSignal(
+		dbus.WithMatchInterface(dbusInterface),
+		dbus.WithMatchObjectPath(dbusPath),
+	); err != nil {
+		return nil, fmt.Errorf("failed to subscribe to notification signals: %w", err)
+	}
+
+	return &dbusBackend{conn: conn, obj: obj}, nil
+}
+
+// Show sends the notification and, when it has actions, waits for the
+// ActionInvoked or NotificationClosed signal carrying its ID. A notification
+// still open after its WaitTimeout is closed and reported as expired.
+func (b *dbusBackend) Show(n Notification) (Result, error) {
+	// Subscribe before sending so no signal for the new ID is missed
+	signals := make(chan *dbus.Signal, 32)
+	b.conn.Signal(signals)
+	defer b.conn.RemoveSignal(signals)
+
+	var actions []string
+	for _, a := range n.Actions {
+		actions = append(actions, a.Key, a.Label)
+	}
+
+	hints := map[string]dbus.Variant{
+		"urgency": dbus.MakeVariant(urgencyLevel(n.Urgency)),
+	}
+	if n.Persistent {
+		hints["resident"] = dbus.MakeVariant(true)
+	}
+	for name, value := range n.Hints {
+		hints[name] = dbus.MakeVariant(value)
+	}
+
+	var id uint32
+	call := b.obj.Call(dbusInterface+".Notify", 0,
+		n.AppName, n.ReplacesID, "", n.Title, n.Body, actions, hints, n.expireTimeout())
+	if err := call.Store(&id); err != nil {
+		return Result{}, fmt.Errorf("failed to send notification: %w", err)
+	}
+
+	result := Result{ID: id}
+	if len(n.Actions) == 0 {
+		return result, nil
+	}
+
+	// A nil channel never fires, so without a WaitTimeout the wait is unbounded
+	var deadline <-chan time.Time
+	if n.WaitTimeout > 0 {
+		timer := time.NewTimer(n.WaitTimeout)
+		defer timer.Stop()
+		deadline = timer.C
+	}
+
+	for {
+		select {
+		case <-deadline:
+			if err := b.CloseNotification(id); err != nil {
+				return result, err
+			}
+			result.CloseReason = CloseExpired
+			return result, nil
+
+		case signal, ok := <-signals:
+			if !ok {
+				return result, fmt.Errorf("session bus closed while waiting for notification %d", id)
+			}
+			if len(signal.Body) < 2 {
+				continue
+			}
+			if signalID, ok := signal.Body[0].(uint32); !ok || signalID != id {
+				continue
+			}
+
+			switch signal.Name {
+			case dbusInterface + ".ActionInvoked":
+				result.Action, _ = signal.Body[1].(string)
+				return result, nil
+			case dbusInterface + ".NotificationClosed":
+				reason, _ := signal.Body[1].(uint32)
+				result.CloseReason = CloseReason(reason)
+				return result, nil
+			}
+		}
+	}
+}
+
+// CloseNotification asks the server to remove the notification
+func (b *dbusBackend) CloseNotification(id uint32) error {
+	if err := b.obj.Call(dbusInterface+".CloseNotification", 0, id).Err; err != nil {
+		return fmt.Errorf("failed to close notification %d: %w", id, err)
+	}
+	return nil
+}
+
+// Close closes the session bus connection
+func (b *dbusBackend) Close() error {
+	return b.conn.Close()
+}
+
+// urgencyLevel converts an urgency name to the byte the urgency hint expects
+func urgencyLevel(urgency string) byte {
+	switch urgency {
+	case "low":
+		return 0
+	case "critical":
+		return 2
+	default:
+		return 1
+	}
+}
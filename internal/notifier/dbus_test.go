@@ -0,0 +1,321 @@
+package notifier
+
+import (
+	"bufio"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// startPrivateBus runs a dbus-daemon for the test and returns its address.
+// The test is skipped when dbus-daemon is not installed.
+func startPrivateBus(t *testing.T) string {
+	t.Helper()
+
+	path, err := exec.LookPath("dbus-daemon")
+	if err != nil {
+		t.Skip("dbus-daemon not installed")
+	}
+
+	cmd := exec.Command(path, "--session", "--nofork", "--print-address")
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		t.Fatalf("Failed to get dbus-daemon output: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start dbus-daemon: %v", err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	address, err := bufio.NewReader(stdout).ReadString('\n')
+	if err != nil {
+		t.Fatalf("Failed to read bus address: %v", err)
+	}
+
+	return strings.TrimSpace(address)
+}
+
+// fakeServer is a notification server that answers every notification by
+// emitting a signal: ActionInvoked with action, or NotificationClosed with
+// closeReason when action is empty. A silent server never answers.
+type fakeServer struct {
+	conn        *dbus.Conn
+	action      string
+	closeReason uint32
+	silent      bool
+
+	replacesID uint32
+	timeout    int32
+	actions    []string
+	hints      map[string]dbus.Variant
+	closed     []uint32
+}
+
+func (s *fakeServer) GetCapabilities() ([]string, *dbus.Error) {
+	return []string{"actions", "body"}, nil
+}
+
+func (s *fakeServer) Notify(appName string, replacesID uint32, icon, summary, body string,
+	actions []string, hints map[string]dbus.Variant, timeout int32) (uint32, *dbus.Error) {
+	s.replacesID = replacesID
+	s.actions = actions
+	s.hints = hints
+	s.timeout = timeout
+
+	id := replacesID
+	if id == 0 {
+		id = 42
+	}
+	if s.silent {
+		return id, nil
+	}
+
+	// An unrelated notification's signal must be ignored
+	_ = s.conn.Emit(dbusPath, dbusInterface+".NotificationClosed", id+1, uint32(CloseExpired))
+	if s.action != "" {
+		_ = s.conn.Emit(dbusPath, dbusInterface+".ActionInvoked", id, s.action)
+	} else {
+		_ = s.conn.Emit(dbusPath, dbusInterface+".NotificationClosed", id, s.closeReason)
+	}
+
+	return id, nil
+}
+
+func (s *fakeServer) CloseNotification(id uint32) *dbus.Error {
+	s.closed = append(s.closed, id)
+	return nil
+}
+
+// newTestBackend starts a private bus with server exported on it and returns
+// a backend connected to that bus
+func newTestBackend(t *testing.T, server *fakeServer) *dbusBackend {
+	t.Helper()
+
+	address := startPrivateBus(t)
+
+	serverConn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	t.Cleanup(func() { _ = serverConn.Close() })
+
+	server.conn = serverConn
+	if err := serverConn.Export(server, dbusPath, dbusInterface); err != nil {
+		t.Fatalf("Failed to export server: %v", err)
+	}
+	if reply, err := serverConn.RequestName(dbusName, dbus.NameFlagDoNotQueue); err != nil || reply != dbus.RequestNameReplyPrimaryOwner {
+		t.Fatalf("Failed to own %s: %v", dbusName, err)
+	}
+
+	clientConn, err := dbus.Connect(address)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+
+	backend, err := newDBusBackend(clientConn)
+	if err != nil {
+		t.Fatalf("Failed to create backend: %v", err)
+	}
+	t.Cleanup(func() { _ = backend.Close() })
+
+	return backend
+}
+
+// TestDBusBackend_ActionInvoked tests IDs, replaces_id, hints and action signals
+func TestDBusBackend_ActionInvoked(t *testing.T) {
+	server := &fakeServer{action: "read"}
+	backend := newTestBackend(t, server)
+
+	result, err := backend.Show(Notification{
+		AppName:    "GitHub Notify",
+		ReplacesID: 7,
+		Title:      "GitHub - owner/repo",
+		Body:       "Mentioned in owner/repo: Fix login",
+		Urgency:    "critical",
+		Actions:    []Action{{Key: "default", Label: "Open"}, {Key: "read", Label: "Mark as read"}},
+		Hints:      map[string]interface{}{"category": "im.received"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.ID != 7 || result.Action != "read" {
+		t.Errorf("Expected ID 7 and action 'read', got %+v", result)
+	}
+	if server.replacesID != 7 {
+		t.Errorf("Expected replaces_id 7, got %d", server.replacesID)
+	}
+	if strings.Join(server.actions, ",") != "default,Open,read,Mark as read" {
+		t.Errorf("Unexpected actions: %v", server.actions)
+	}
+	if urgency, ok := server.hints["urgency"].Value().(byte); !ok || urgency != 2 {
+		t.Errorf("Expected urgency hint 2, got %v", server.hints["urgency"])
+	}
+	if category, _ := server.hints["category"].Value().(string); category != "im.received" {
+		t.Errorf("Expected category hint, got %v", server.hints["category"])
+	}
+
+	t.Logf("✓ D-Bus action test passed!")
+}
+
+// TestDBusBackend_Persistent tests that persistent notifications never expire
+// and carry the resident hint
+func TestDBusBackend_Persistent(t *testing.T) {
+	server := &fakeServer{}
+	backend := newTestBackend(t, server)
+
+	if _, err := backend.Show(Notification{Title: "GitHub: 2 unread", Urgency: "low", Persistent: true}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if server.timeout != 0 {
+		t.Errorf("Expected expire timeout 0, got %d", server.timeout)
+	}
+	if resident, _ := server.hints["resident"].Value().(bool); !resident {
+		t.Errorf("Expected the resident hint, got %v", server.hints["resident"])
+	}
+
+	t.Logf("✓ D-Bus persistent notification test passed!")
+}
+
+// TestDBusBackend_Closed tests that close reasons are reported
+func TestDBusBackend_Closed(t *testing.T) {
+	server := &fakeServer{closeReason: uint32(CloseDismissed)}
+	backend := newTestBackend(t, server)
+
+	result, err := backend.Show(Notification{
+		Title:   "GitHub - 3 new notifications",
+		Urgency: "normal",
+		Actions: []Action{{Key: "default", Label: "Open list"}},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.ID != 42 || result.Action != "" || result.CloseReason != CloseDismissed {
+		t.Errorf("Expected dismissed notification 42, got %+v", result)
+	}
+
+	t.Logf("✓ D-Bus close reason test passed!")
+}
+
+// TestDBusBackend_Expired tests that waiting gives up after the wait timeout
+// and closes the unanswered notification, while the server keeps its own expiry
+func TestDBusBackend_Expired(t *testing.T) {
+	server := &fakeServer{silent: true}
+	backend := newTestBackend(t, server)
+
+	result, err := backend.Show(Notification{
+		Title:       "GitHub - owner/repo",
+		Urgency:     "normal",
+		Actions:     []Action{{Key: "default", Label: "Open"}},
+		WaitTimeout: 50 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if result.ID != 42 || result.CloseReason != CloseExpired {
+		t.Errorf("Expected expired notification 42, got %+v", result)
+	}
+	if server.timeout != -1 {
+		t.Errorf("Expected the server's default expire timeout, got %d", server.timeout)
+	}
+	if len(server.closed) != 1 || server.closed[0] != 42 {
+		t.Errorf("Expected notification 42 to be closed, got %v", server.closed)
+	}
+
+	t.Logf("✓ D-Bus expiry test passed!")
+}
+
+// TestDBusBackend_CloseNotification tests that notifications are closed by ID
+func TestDBusBackend_CloseNotification(t *testing.T) {
+	server := &fakeServer{}
+	backend := newTestBackend(t, server)
+
+	if err := backend.CloseNotification(42); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(server.closed) != 1 || server.closed[0] != 42 {
+		t.Errorf("Expected notification 42 to be closed, got %v", server.closed)
+	}
+
+	t.Logf("✓ D-Bus close notification test passed!")
+}
+
+// TestFormatHint tests notify-send hint formatting
+func TestFormatHint(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected string
+	}{
+		{"im.received", "string:name:im.received"},
+		{true, "boolean:name:true"},
+		{byte(2), "byte:name:2"},
+		{int32(5), "int:name:5"},
+		{1.5, ""},
+	}
+
+	for _, tt := range tests {
+		if got := formatHint("name", tt.value); got != tt.expected {
+			t.Errorf("formatHint(%v) = %q, want %q", tt.value, got, tt.expected)
+		}
+	}
+
+	t.Logf("✓ Hint format test passed!")
+}
+
+// TestParseNotifySendOutput tests reading the ID and action notify-send prints
+func TestParseNotifySendOutput(t *testing.T) {
+	result, err := parseNotifySendOutput("12\n", true)
+	if err != nil || result.ID != 12 || result.Action != "" {
+		t.Errorf("Expected ID 12 without an action, got %+v (%v)", result, err)
+	}
+
+	result, err = parseNotifySendOutput("12\nopen\n", true)
+	if err != nil || result.ID != 12 || result.Action != "open" {
+		t.Errorf("Expected ID 12 with action 'open', got %+v (%v)", result, err)
+	}
+
+	if _, err := parseNotifySendOutput("open\n", true); err == nil {
+		t.Error("Expected an error for output without an ID")
+	}
+
+	// Versions without --print-id only print the action
+	result, err = parseNotifySendOutput("open\n", false)
+	if err != nil || result.ID != 0 || result.Action != "open" {
+		t.Errorf("Expected action 'open' without an ID, got %+v (%v)", result, err)
+	}
+
+	t.Logf("✓ notify-send output test passed!")
+}
+
+// TestNotifySendArgs tests that the ID flags are only passed to notify-send
+// versions that know them
+func TestNotifySendArgs(t *testing.T) {
+	n := Notification{AppName: "gh-notify", ReplacesID: 7, Title: "Title", Body: "Body", Urgency: "normal"}
+
+	args := strings.Join(notifySendArgs(n, true), " ")
+	if !strings.Contains(args, "--print-id") || !strings.Contains(args, "--replace-id=7") {
+		t.Errorf("Expected the ID flags, got %q", args)
+	}
+
+	args = strings.Join(notifySendArgs(n, false), " ")
+	if strings.Contains(args, "--print-id") || strings.Contains(args, "--replace-id") {
+		t.Errorf("Expected no ID flags for older notify-send, got %q", args)
+	}
+
+	n.Persistent = true
+	args = strings.Join(notifySendArgs(n, true), " ")
+	if !strings.Contains(args, "--expire-time=0") || !strings.Contains(args, "--hint=boolean:resident:true") {
+		t.Errorf("Expected a persistent notification to never expire, got %q", args)
+	}
+
+	t.Logf("✓ notify-send arguments test passed!")
+}
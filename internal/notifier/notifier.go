@@ -2,15 +2,22 @@ package notifier
 
 import (
 	"fmt"
-	"os/exec"
 	"strings"
 
 	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/catalog"
 	"github.com/bnema/gh-notify/internal/nerdfonts"
+	"github.com/bnema/gh-notify/internal/notifytemplate"
 )
 
 type Notifier struct {
-	enabled bool
+	enabled    bool
+	urgency    string                   // Overrides per-reason urgency when non-empty
+	host       string                   // GitHub hostname opened by the default action
+	account    string                   // Account name shown in titles when several are polled
+	dispatcher Dispatcher               // Waits for buttons; nil waits inline with Open buttons only
+	backend    Backend                  // Chosen on first use when not set
+	templates  notifytemplate.Templates // User templates; empty ones use the built-in text
 }
 
 func New(enabled bool) *Notifier {
@@ -26,9 +33,24 @@ func (n *Notifier) SendNotification(entry cache.CacheEntry) error {
 
 	title := n.formatTitle(entry)
 	message := n.formatMessage(entry)
-	urgency := n.getUrgency(entry.Reason)
+	urgency := n.entryUrgency(entry)
 
-	return n.sendNotifyNotification(title, message, urgency)
+	// Clicking a single notification opens the exact item
+	url := entry.WebURL
+	if url == "" {
+		url = n.notificationsURL()
+	}
+
+	buttons := []Button{{Key: "open", Label: "Open", Kind: ButtonOpen, URL: url}}
+	if n.dispatcher != nil {
+		entries := []cache.CacheEntry{entry}
+		buttons = append(buttons,
+			Button{Key: "read", Label: "Mark as read", Kind: ButtonRead, Entries: entries},
+			Button{Key: "mute", Label: "Mute", Kind: ButtonMute, Entries: entries},
+		)
+	}
+
+	return n.sendNotifyNotification(title, message, urgency, buttons)
 }
 
 func (n *Notifier) SendBulkNotification(entries []cache.CacheEntry) error {
@@ -41,10 +63,10 @@ func (n *Notifier) SendBulkNotification(entries []cache.CacheEntry) error {
 	}
 
 	// For multiple notifications, send a summary
-	title := fmt.Sprintf("GitHub - %d new notifications", len(entries))
+	title := n.formatBulkTitle(entries)
 	message := n.formatBulkMessage(entries)
 
-	return n.sendNotifyNotification(title, message, "normal")
+	return n.sendNotifyNotification(title, message, n.bulkUrgency(entries), n.bulkButtons(entries))
 }
 
 // SendStarNotifications sends notifications for new star events
@@ -58,22 +80,108 @@ func (n *Notifier) SendStarNotifications(starEvents []cache.StarEvent) error {
 	}
 
 	// For multiple star events, send a summary
-	title := fmt.Sprintf("%s %d new stars!", nerdfonts.StarredRepo, len(starEvents))
+	title := n.formatStarTitle(starEvents)
 	message := n.formatStarBulkMessage(starEvents)
 
-	return n.sendNotifyNotification(title, message, "normal")
+	return n.sendNotifyNotification(title, message, n.getUrgency("", ""), n.listButtons())
+}
+
+// SendCatchUpNotification sends one summary of the notifications and star
+// events held back during quiet hours
+func (n *Notifier) SendCatchUpNotification(entries []cache.CacheEntry, starEvents []cache.StarEvent) error {
+	if !n.enabled || (len(entries) == 0 && len(starEvents) == 0) {
+		return nil
+	}
+
+	var counts, sections []string
+	if len(entries) > 0 {
+		counts = append(counts, fmt.Sprintf("%d notifications", len(entries)))
+		sections = append(sections, n.formatBulkMessage(entries))
+	}
+	if len(starEvents) > 0 {
+		counts = append(counts, fmt.Sprintf("%d stars", len(starEvents)))
+		sections = append(sections, n.formatStarBulkMessage(starEvents))
+	}
+
+	title := fmt.Sprintf("%s - %s during quiet hours", n.appTitle(), strings.Join(counts, " and "))
+	message := strings.Join(sections, "\n")
+
+	return n.sendNotifyNotification(title, message, n.bulkUrgency(entries), n.bulkButtons(entries))
+}
+
+// SendSummary shows the persistent "GitHub: N unread" summary of unread,
+// replacing the summary shown earlier with ID replacesID (0 shows a new one).
+// Its urgency follows newEntries, the notifications that are new since the
+// last update. It has no buttons, so it never waits, and returns the ID to
+// replace next time.
+func (n *Notifier) SendSummary(unread, newEntries []cache.CacheEntry, replacesID uint32) (uint32, error) {
+	if !n.enabled || len(unread) == 0 {
+		return replacesID, nil
+	}
+
+	backend, err := n.getBackend()
+	if err != nil {
+		return replacesID, err
+	}
+
+	urgency := "low"
+	if len(newEntries) > 0 {
+		urgency = n.bulkUrgency(newEntries)
+	}
+
+	message := n.formatBulkMessage(unread)
+	if len(unread) == 1 {
+		message = n.formatMessage(unread[0])
+	}
+
+	result, err := backend.Show(Notification{
+		AppName:    "GitHub Notify",
+		ReplacesID: replacesID,
+		Title:      fmt.Sprintf("%s: %d unread", n.appTitle(), len(unread)),
+		Body:       message,
+		Urgency:    urgency,
+		Persistent: true,
+	})
+	if err != nil {
+		return replacesID, err
+	}
+
+	return result.ID, nil
+}
+
+// CloseSummary removes the summary with the given ID, e.g. once nothing is
+// unread
+func (n *Notifier) CloseSummary(id uint32) error {
+	if id == 0 {
+		return nil
+	}
+
+	backend, err := n.getBackend()
+	if err != nil {
+		return err
+	}
+
+	return backend.CloseNotification(id)
 }
 
 // sendStarNotification sends a single star event notification
 func (n *Notifier) sendStarNotification(star cache.StarEvent) error {
-	title := fmt.Sprintf("%s New Star!", nerdfonts.StarredRepo)
-	message := fmt.Sprintf("%s starred your repository: %s", star.StarredBy, star.Repository)
+	stars := []cache.StarEvent{star}
+	title := n.formatStarTitle(stars)
+	message, ok := n.templates.Star.Body(n.starData(stars))
+	if !ok {
+		message = fmt.Sprintf("%s starred your repository: %s", star.StarredBy, star.Repository)
+	}
 
-	return n.sendNotifyNotification(title, message, "normal")
+	return n.sendNotifyNotification(title, message, n.getUrgency("", ""), n.listButtons())
 }
 
 // formatStarBulkMessage formats multiple star events into a summary message
 func (n *Notifier) formatStarBulkMessage(starEvents []cache.StarEvent) string {
+	if message, ok := n.templates.Star.Body(n.starData(starEvents)); ok {
+		return message
+	}
+
 	var lines []string
 
 	// Group by repository
@@ -108,25 +216,63 @@ func (n *Notifier) formatStarBulkMessage(starEvents []cache.StarEvent) string {
 	return strings.Join(lines, "\n")
 }
 
+// formatStarTitle titles a notification about one or more star events
+func (n *Notifier) formatStarTitle(starEvents []cache.StarEvent) string {
+	if title, ok := n.templates.Star.Title(n.starData(starEvents)); ok {
+		return title
+	}
+
+	if len(starEvents) == 1 {
+		return fmt.Sprintf("%s New Star!%s", nerdfonts.StarredRepo, n.accountSuffix())
+	}
+	return fmt.Sprintf("%s %d new stars!%s", nerdfonts.StarredRepo, len(starEvents), n.accountSuffix())
+}
+
 func (n *Notifier) formatTitle(entry cache.CacheEntry) string {
-	return fmt.Sprintf("GitHub - %s", entry.Repository)
+	if title, ok := n.templates.Single.Title(n.entryData(entry)); ok {
+		return title
+	}
+
+	return fmt.Sprintf("%s - %s", n.appTitle(), entry.Repository)
 }
 
 func (n *Notifier) formatMessage(entry cache.CacheEntry) string {
-	reasonText := n.formatReason(entry.Reason)
+	if message, ok := n.templates.Single.Body(n.entryData(entry)); ok {
+		return message
+	}
+
+	reasonText := catalog.Reason(entry.Reason).Label
 
 	// Include repository name in the message body as requested
 	var message string
 	if entry.Type != "" {
-		message = fmt.Sprintf("%s [%s] in %s: %s", reasonText, entry.Type, entry.Repository, entry.Title)
+		message = fmt.Sprintf("%s [%s] in %s: %s", reasonText, catalog.Type(entry.Type).Label, entry.Repository, entry.Title)
 	} else {
 		message = fmt.Sprintf("%s in %s: %s", reasonText, entry.Repository, entry.Title)
 	}
 
+	// e.g. "(merged)" so a stale review request is obvious
+	if state := entry.Subject.Label(); state != "" {
+		message += fmt.Sprintf(" (%s)", state)
+	}
+
 	return message
 }
 
+// formatBulkTitle titles a summary of several notifications
+func (n *Notifier) formatBulkTitle(entries []cache.CacheEntry) string {
+	if title, ok := n.templates.Bulk.Title(n.bulkData(entries)); ok {
+		return title
+	}
+
+	return fmt.Sprintf("%s - %d new notifications", n.appTitle(), len(entries))
+}
+
 func (n *Notifier) formatBulkMessage(entries []cache.CacheEntry) string {
+	if message, ok := n.templates.Bulk.Body(n.bulkData(entries)); ok {
+		return message
+	}
+
 	var lines []string
 
 	// Group by repository
@@ -155,79 +301,98 @@ func (n *Notifier) formatBulkMessage(entries []cache.CacheEntry) string {
 	return strings.Join(lines, "\n")
 }
 
-func (n *Notifier) formatReason(reason string) string {
-	switch reason {
-	case "assign":
-		return "Assigned"
-	case "author":
-		return "Author update"
-	case "comment":
-		return "New comment"
-	case "invitation":
-		return "Invitation"
-	case "manual":
-		return "Manual subscription"
-	case "mention":
-		return "Mentioned"
-	case "review_requested":
-		return "Review requested"
-	case "security_alert":
-		return "Security alert"
-	case "state_change":
-		return "State changed"
-	case "subscribed":
-		return "Subscribed"
-	case "team_mention":
-		return "Team mentioned"
-	default:
-		return "Notification"
-	}
-}
-
-func (n *Notifier) getUrgency(reason string) string {
-	switch reason {
-	case "security_alert":
-		return "critical"
-	case "assign", "review_requested", "mention", "team_mention":
-		return "normal"
-	default:
-		return "normal"
-	}
-}
-
-// sendNotifyNotification sends a notification using notify-send with clickable default action
-func (n *Notifier) sendNotifyNotification(title, message, urgency string) error {
-	args := []string{
-		"--app-name=GitHub Notify",
-		"--urgency=" + urgency,
-		"--action", "default=Open GitHub Notifications",
-		"--wait",
-		title,
-		message,
-	}
-
-	cmd := exec.Command("notify-send", args...)
-	output, err := cmd.CombinedOutput()
+// getUrgency returns the configured urgency, or the catalog default for the
+// reason and subject type
+func (n *Notifier) getUrgency(reason, subjectType string) string {
+	if n.urgency != "" {
+		return n.urgency
+	}
+	return catalog.Urgency(reason, subjectType)
+}
+
+// bulkUrgency returns the highest urgency of the entries, where rules
+// override the default urgency of an entry
+func (n *Notifier) bulkUrgency(entries []cache.CacheEntry) string {
+	urgency := ""
+	for _, entry := range entries {
+		urgency = catalog.HigherUrgency(urgency, n.entryUrgency(entry))
+	}
+
+	if urgency == "" {
+		return n.getUrgency("", "")
+	}
+	return urgency
+}
+
+// entryUrgency returns the rule urgency of an entry, or its default urgency
+func (n *Notifier) entryUrgency(entry cache.CacheEntry) string {
+	if entry.Urgency != "" {
+		return entry.Urgency
+	}
+	return n.getUrgency(entry.Reason, entry.Type)
+}
+
+// listButtons opens the notifications list
+func (n *Notifier) listButtons() []Button {
+	return []Button{{Key: "open", Label: "Open list", Kind: ButtonOpen, URL: n.notificationsURL()}}
+}
+
+// bulkButtons opens the notifications list or marks every entry as read
+func (n *Notifier) bulkButtons(entries []cache.CacheEntry) []Button {
+	buttons := n.listButtons()
+	if n.dispatcher != nil && len(entries) > 0 {
+		buttons = append(buttons, Button{Key: "read-all", Label: "Mark all read", Kind: ButtonRead, Entries: entries})
+	}
+	return buttons
+}
+
+// sendNotifyNotification shows a notification with one button per entry of
+// buttons. A dispatcher, when set, waits for the user so the caller does not
+// block; otherwise this waits and opens the chosen link itself.
+func (n *Notifier) sendNotifyNotification(title, message, urgency string, buttons []Button) error {
+	pending := Pending{
+		Notification: Notification{
+			AppName: "GitHub Notify",
+			Title:   title,
+			Body:    message,
+			Urgency: urgency,
+		},
+		Buttons: buttons,
+	}
+
+	if n.dispatcher != nil {
+		return n.dispatcher.Dispatch(pending)
+	}
+
+	backend, err := n.getBackend()
 	if err != nil {
-		return fmt.Errorf("notify-send failed: %w, output: %s", err, string(output))
+		return err
+	}
+
+	button, err := Wait(backend, pending)
+	if err != nil || button == nil {
+		return err
 	}
 
-	// Handle the action response (this runs in background)
-	go n.handleNotificationAction(strings.TrimSpace(string(output)))
+	// Log error but don't fail - buttons are best effort
+	if err := button.Run(nil); err != nil {
+		fmt.Printf("Warning: notification action %q failed: %v\n", button.Label, err)
+	}
 
 	return nil
 }
 
-// handleNotificationAction processes the action response from notify-send
-func (n *Notifier) handleNotificationAction(response string) {
-	if response == "default" {
-		// Open GitHub notifications page in default browser
-		cmd := exec.Command("xdg-open", "https://github.com/notifications")
-		if err := cmd.Run(); err != nil {
-			// Log error but don't fail - browser opening is not critical
-			fmt.Printf("Warning: failed to open browser: %v\n", err)
+// getBackend returns the configured backend, choosing one automatically on
+// first use when none was set
+func (n *Notifier) getBackend() (Backend, error) {
+	if n.backend == nil {
+		backend, err := NewBackend(BackendAuto)
+		if err != nil {
+			return nil, err
 		}
+		n.backend = backend
 	}
+	return n.backend, nil
 }
 
 func (n *Notifier) IsEnabled() bool {
@@ -237,3 +402,87 @@ func (n *Notifier) IsEnabled() bool {
 func (n *Notifier) SetEnabled(enabled bool) {
 	n.enabled = enabled
 }
+
+// SetUrgency overrides the urgency of all notifications (low, normal, critical).
+// An empty string restores the default per-reason urgency.
+func (n *Notifier) SetUrgency(urgency string) {
+	n.urgency = urgency
+}
+
+// SetHost sets the GitHub hostname whose notifications page the default
+// action opens, e.g. a GitHub Enterprise Server. Empty means github.com.
+func (n *Notifier) SetHost(host string) {
+	n.host = host
+}
+
+func (n *Notifier) notificationsURL() string {
+	host := n.host
+	if host == "" {
+		host = "github.com"
+	}
+	return fmt.Sprintf("https://%s/notifications", host)
+}
+
+// SetBackend sets the backend that displays notifications when no
+// dispatcher is set
+func (n *Notifier) SetBackend(backend Backend) {
+	n.backend = backend
+}
+
+// SetDispatcher hands notifications to d, which waits for the user outside the
+// caller and runs the chosen button. It also enables the "Mark as read",
+// "Mute" and "Mark all read" buttons. nil restores waiting inline.
+func (n *Notifier) SetDispatcher(d Dispatcher) {
+	n.dispatcher = d
+}
+
+// SetAccount sets the account name shown in notification titles, so alerts
+// from several polled accounts can be told apart. Empty hides it.
+func (n *Notifier) SetAccount(account string) {
+	n.account = account
+}
+
+// SetTemplates sets the templates for notification text. nil restores the
+// built-in text.
+func (n *Notifier) SetTemplates(t *notifytemplate.Templates) {
+	if t == nil {
+		t = &notifytemplate.Templates{}
+	}
+	n.templates = *t
+}
+
+// entryData is the template data for a single notification
+func (n *Notifier) entryData(entry cache.CacheEntry) notifytemplate.EntryData {
+	if entry.Account == "" {
+		entry.Account = n.account
+	}
+	return notifytemplate.EntryData{CacheEntry: entry, App: n.appTitle()}
+}
+
+// bulkData is the template data for a summary of several notifications
+func (n *Notifier) bulkData(entries []cache.CacheEntry) notifytemplate.BulkData {
+	return notifytemplate.BulkData{
+		App:          n.appTitle(),
+		Account:      n.account,
+		Count:        len(entries),
+		Entries:      entries,
+		Repositories: notifytemplate.RepositoryCounts(entries),
+	}
+}
+
+// starData is the template data for star events
+func (n *Notifier) starData(starEvents []cache.StarEvent) notifytemplate.StarData {
+	return notifytemplate.StarData{App: n.appTitle(), Account: n.account, Count: len(starEvents), Stars: starEvents}
+}
+
+// appTitle is the title prefix, e.g. "GitHub" or "GitHub (work)"
+func (n *Notifier) appTitle() string {
+	return "GitHub" + n.accountSuffix()
+}
+
+func (n *Notifier) accountSuffix() string {
+	if n.account == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", n.account)
+}
@@ -0,0 +1,165 @@
+package notifier
+
+import (
+	"testing"
+
+	"github.com/bnema/gh-notify/internal/cache"
+)
+
+// TestChosenButton tests that backend action keys pick the matching button
+func TestChosenButton(t *testing.T) {
+	buttons := []Button{
+		{Key: "open", Label: "Open", Kind: ButtonOpen},
+		{Key: "read", Label: "Mark as read", Kind: ButtonRead},
+		{Key: "mute", Label: "Mute", Kind: ButtonMute},
+	}
+
+	tests := []struct {
+		key      string
+		expected string
+	}{
+		{"default", "open"}, // body click picks the first button
+		{"mute", "mute"},
+		{"", ""}, // dismissed
+		{"unknown", ""},
+	}
+
+	for _, tt := range tests {
+		button := chosenButton(tt.key, buttons)
+		got := ""
+		if button != nil {
+			got = button.Key
+		}
+		if got != tt.expected {
+			t.Errorf("chosenButton(%q) = %q, want %q", tt.key, got, tt.expected)
+		}
+	}
+
+	t.Logf("✓ Chosen button test passed!")
+}
+
+// fakeBackend answers every notification with a fixed action
+type fakeBackend struct {
+	action string
+	shown  Notification
+	closed []uint32
+}
+
+func (b *fakeBackend) Show(n Notification) (Result, error) {
+	b.shown = n
+	return Result{ID: 1, Action: b.action}, nil
+}
+
+func (b *fakeBackend) CloseNotification(id uint32) error {
+	b.closed = append(b.closed, id)
+	return nil
+}
+
+func (b *fakeBackend) Close() error {
+	return nil
+}
+
+// recordingDispatcher keeps dispatched notifications instead of showing them
+type recordingDispatcher struct {
+	pending []Pending
+}
+
+func (d *recordingDispatcher) Dispatch(p Pending) error {
+	d.pending = append(d.pending, p)
+	return nil
+}
+
+// TestWait tests that buttons become backend actions and the answer maps back
+func TestWait(t *testing.T) {
+	backend := &fakeBackend{action: "read"}
+	pending := Pending{
+		Notification: Notification{Title: "GitHub - owner/repo"},
+		Buttons: []Button{
+			{Key: "open", Label: "Open", Kind: ButtonOpen},
+			{Key: "read", Label: "Mark as read", Kind: ButtonRead},
+		},
+	}
+
+	button, err := Wait(backend, pending)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if button == nil || button.Kind != ButtonRead {
+		t.Errorf("Expected the read button, got %+v", button)
+	}
+	if len(backend.shown.Actions) != 3 || backend.shown.Actions[0] != (Action{Key: "default", Label: "Open"}) {
+		t.Errorf("Expected default action plus one per button, got %+v", backend.shown.Actions)
+	}
+
+	t.Logf("✓ Wait test passed!")
+}
+
+// TestSendSummary tests that summaries replace the given ID and close by ID
+func TestSendSummary(t *testing.T) {
+	backend := &fakeBackend{}
+	n := New(true)
+	n.SetBackend(backend)
+	n.SetAccount("work")
+
+	unread := []cache.CacheEntry{
+		{Repository: "owner/repo", Reason: "mention"},
+		{Repository: "owner/repo", Reason: "security_alert"},
+	}
+	id, err := n.SendSummary(unread, nil, 5)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if id != 1 || backend.shown.ReplacesID != 5 {
+		t.Errorf("Expected summary 5 replaced and ID 1 returned, got ID %d replacing %d", id, backend.shown.ReplacesID)
+	}
+	if backend.shown.Title != "GitHub (work): 2 unread" || backend.shown.Body != "• owner/repo (2)" {
+		t.Errorf("Unexpected summary: %q / %q", backend.shown.Title, backend.shown.Body)
+	}
+	// Nothing new, so the update is quiet
+	if backend.shown.Urgency != "low" {
+		t.Errorf("Expected low urgency without new notifications, got %q", backend.shown.Urgency)
+	}
+
+	if _, err := n.SendSummary(unread, unread[1:], 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if backend.shown.Urgency != "critical" {
+		t.Errorf("Expected the urgency of the new notification, got %q", backend.shown.Urgency)
+	}
+
+	if err := n.CloseSummary(1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if err := n.CloseSummary(0); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(backend.closed) != 1 || backend.closed[0] != 1 {
+		t.Errorf("Expected only summary 1 to be closed, got %v", backend.closed)
+	}
+
+	t.Logf("✓ Summary notification test passed!")
+}
+
+// TestSendNotification_Dispatcher tests that a dispatcher gets the notification with thread buttons
+func TestSendNotification_Dispatcher(t *testing.T) {
+	dispatcher := &recordingDispatcher{}
+	n := New(true)
+	n.SetDispatcher(dispatcher)
+
+	entry := cache.CacheEntry{ID: "1", Repository: "owner/repo", Title: "Fix login", Reason: "mention", WebURL: "https://github.com/owner/repo/issues/1"}
+	if err := n.SendNotification(entry); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(dispatcher.pending) != 1 {
+		t.Fatalf("Expected one dispatched notification, got %d", len(dispatcher.pending))
+	}
+	buttons := dispatcher.pending[0].Buttons
+	if len(buttons) != 3 || buttons[0].URL != entry.WebURL || buttons[2].Kind != ButtonMute || buttons[2].Entries[0].ID != "1" {
+		t.Errorf("Unexpected buttons: %+v", buttons)
+	}
+
+	t.Logf("✓ Dispatcher notification test passed!")
+}
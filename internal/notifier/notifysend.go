@@ -0,0 +1,165 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// notifySendBackend shells out to notify-send (libnotify 0.7.9 or later, for
+// actions). Notification IDs need libnotify 0.8; older versions return no ID
+// and add a new notification instead of replacing one. notify-send cannot
+// close a notification, so CloseNotification calls the server through gdbus.
+type notifySendBackend struct {
+	detectOnce sync.Once
+	printsID   bool
+}
+
+// Show runs notify-send, waiting for the chosen action when there are actions
+func (b *notifySendBackend) Show(n Notification) (Result, error) {
+	printsID := b.supportsIDs()
+	args := notifySendArgs(n, printsID)
+
+	ctx := context.Background()
+	if n.WaitTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, n.WaitTimeout)
+		defer cancel()
+	}
+
+	output, err := exec.CommandContext(ctx, "notify-send", args...).Output()
+	if err != nil && ctx.Err() != nil {
+		if !printsID {
+			return Result{CloseReason: CloseExpired}, nil
+		}
+		// notify-send printed the ID before it started waiting
+		result, parseErr := parseNotifySendOutput(string(output), true)
+		if parseErr != nil {
+			return Result{}, fmt.Errorf("notify-send timed out: %w", ctx.Err())
+		}
+		if err := b.CloseNotification(result.ID); err != nil {
+			return result, err
+		}
+		result.CloseReason = CloseExpired
+		return result, nil
+	}
+	if err != nil {
+		return Result{}, fmt.Errorf("notify-send failed: %w, output: %s", err, commandStderr(err))
+	}
+
+	return parseNotifySendOutput(string(output), printsID)
+}
+
+// supportsIDs reports whether notify-send has --print-id and --replace-id,
+// checking its help output once
+func (b *notifySendBackend) supportsIDs() bool {
+	b.detectOnce.Do(func() {
+		output, err := exec.Command("notify-send", "--help").Output()
+		b.printsID = err == nil && strings.Contains(string(output), "--print-id")
+	})
+	return b.printsID
+}
+
+// notifySendArgs builds the notify-send command line. The ID flags are left
+// out when printsID is false, as older versions reject them.
+func notifySendArgs(n Notification, printsID bool) []string {
+	args := []string{
+		"--app-name=" + n.AppName,
+		"--urgency=" + n.Urgency,
+	}
+	if printsID {
+		args = append(args, "--print-id")
+		if n.ReplacesID != 0 {
+			args = append(args, "--replace-id="+strconv.FormatUint(uint64(n.ReplacesID), 10))
+		}
+	}
+	if n.Persistent {
+		args = append(args, "--expire-time=0", "--hint=boolean:resident:true")
+	}
+
+	// Sorted so the command line is stable
+	names := make([]string, 0, len(n.Hints))
+	for name := range n.Hints {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if hint := formatHint(name, n.Hints[name]); hint != "" {
+			args = append(args, "--hint="+hint)
+		}
+	}
+
+	for _, a := range n.Actions {
+		args = append(args, "--action", a.Key+"="+a.Label)
+	}
+	if len(n.Actions) > 0 {
+		args = append(args, "--wait")
+	}
+	return append(args, n.Title, n.Body)
+}
+
+// CloseNotification closes a notification shown earlier
+func (b *notifySendBackend) CloseNotification(id uint32) error {
+	cmd := exec.Command("gdbus", "call", "--session",
+		"--dest", dbusName,
+		"--object-path", string(dbusPath),
+		"--method", dbusInterface+".CloseNotification",
+		strconv.FormatUint(uint64(id), 10))
+	if _, err := cmd.Output(); err != nil {
+		return fmt.Errorf("failed to close notification %d: %w, output: %s", id, err, commandStderr(err))
+	}
+	return nil
+}
+
+// Close is a no-op; notify-send holds no resources between notifications
+func (b *notifySendBackend) Close() error {
+	return nil
+}
+
+// parseNotifySendOutput reads the notification ID that --print-id writes on
+// the first line when withID is set, followed by the chosen action key when
+// waiting for one
+func parseNotifySendOutput(output string, withID bool) (Result, error) {
+	if !withID {
+		return Result{Action: strings.TrimSpace(output)}, nil
+	}
+
+	idLine, action, _ := strings.Cut(strings.TrimSpace(output), "\n")
+
+	id, err := strconv.ParseUint(strings.TrimSpace(idLine), 10, 32)
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to parse notify-send notification ID %q: %w", idLine, err)
+	}
+
+	return Result{ID: uint32(id), Action: strings.TrimSpace(action)}, nil
+}
+
+// commandStderr returns what a failed command wrote to stderr
+func commandStderr(err error) string {
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return strings.TrimSpace(string(exitErr.Stderr))
+	}
+	return ""
+}
+
+// formatHint formats a hint as notify-send's TYPE:NAME:VALUE, or returns an
+// empty string for value types notify-send cannot express
+func formatHint(name string, value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return fmt.Sprintf("string:%s:%s", name, v)
+	case bool:
+		return fmt.Sprintf("boolean:%s:%t", name, v)
+	case byte:
+		return fmt.Sprintf("byte:%s:%d", name, v)
+	case int, int32:
+		return fmt.Sprintf("int:%s:%d", name, v)
+	}
+	return ""
+}
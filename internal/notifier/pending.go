@@ -0,0 +1,113 @@
+package notifier
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+)
+
+// Button kinds
+const (
+	ButtonOpen = "open" // Open URL in the browser
+	ButtonRead = "read" // Mark Entries as read
+	ButtonMute = "mute" // Mute the first of Entries
+)
+
+// Button is a notification button and what it does
+type Button struct {
+	Key     string             `json:"key"`
+	Label   string             `json:"label"`
+	Kind    string             `json:"kind"`
+	URL     string             `json:"url,omitempty"`
+	Entries []cache.CacheEntry `json:"entries,omitempty"`
+}
+
+// Pending is a notification together with its buttons. It is plain data so
+// another process, such as a detached helper, can show it and run the button
+// the user chooses.
+type Pending struct {
+	Notification Notification `json:"notification"`
+	Buttons      []Button     `json:"buttons"`
+}
+
+// Dispatcher shows pending notifications and runs the chosen button without
+// blocking the caller
+type Dispatcher interface {
+	Dispatch(p Pending) error
+}
+
+// ThreadActions carries out the thread buttons on GitHub and keeps the cache
+// in step
+type ThreadActions interface {
+	MarkRead(entries []cache.CacheEntry) error
+	Mute(entry cache.CacheEntry) error
+}
+
+// ButtonTimeout bounds how long Wait waits for the user to press a button,
+// unless the notification sets its own WaitTimeout. The notification itself
+// expires whenever the server decides; an unanswered one is closed after this.
+const ButtonTimeout = time.Hour
+
+// Wait shows p with backend and blocks until the user answers, the
+// notification is closed or ButtonTimeout passes. It returns the chosen button, or nil when the
+// notification closed without one. Clicking the notification body chooses
+// the first button.
+func Wait(backend Backend, p Pending) (*Button, error) {
+	notification := p.Notification
+	notification.Actions = nil
+	if len(p.Buttons) > 0 && notification.WaitTimeout == 0 {
+		notification.WaitTimeout = ButtonTimeout
+	}
+	if len(p.Buttons) > 0 {
+		notification.Actions = append(notification.Actions, Action{Key: "default", Label: p.Buttons[0].Label})
+	}
+	for _, b := range p.Buttons {
+		notification.Actions = append(notification.Actions, Action{Key: b.Key, Label: b.Label})
+	}
+
+	result, err := backend.Show(notification)
+	if err != nil {
+		return nil, err
+	}
+
+	return chosenButton(result.Action, p.Buttons), nil
+}
+
+// chosenButton returns the button named by an action key; "default" is the
+// first button
+func chosenButton(key string, buttons []Button) *Button {
+	if key == "" || len(buttons) == 0 {
+		return nil
+	}
+
+	if key == "default" {
+		key = buttons[0].Key
+	}
+
+	for i := range buttons {
+		if buttons[i].Key == key {
+			return &buttons[i]
+		}
+	}
+	return nil
+}
+
+// Run carries out the button. Thread buttons need threads.
+func (b *Button) Run(threads ThreadActions) error {
+	switch b.Kind {
+	case ButtonOpen:
+		return exec.Command("xdg-open", b.URL).Run()
+	case ButtonRead, ButtonMute:
+		if threads == nil || len(b.Entries) == 0 {
+			return fmt.Errorf("no threads to update for %q", b.Label)
+		}
+		if b.Kind == ButtonMute {
+			return threads.Mute(b.Entries[0])
+		}
+		return threads.MarkRead(b.Entries)
+	default:
+		return fmt.Errorf("unknown button kind %q", b.Kind)
+	}
+}
@@ -0,0 +1,98 @@
+package notifier
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/notifytemplate"
+)
+
+// TestTemplates_Render tests single, bulk and star templates and their helpers
+func TestTemplates_Render(t *testing.T) {
+	templates, err := notifytemplate.New(notifytemplate.Config{
+		Single: notifytemplate.Message{
+			Title: "{{ .Repository }} ({{ .Account }})",
+			Body:  "{{ reason .Reason }} {{ subjectType .Type }}: {{ .Title | truncate 10 }} [{{ state .Subject }}] {{ age .UpdatedAt }}",
+		},
+		Bulk: notifytemplate.Message{
+			Title: "{{ .Count }} for {{ .App }}",
+			Body:  "{{ range .Repositories }}{{ .Repository }}={{ .Count }} {{ end }}",
+		},
+		Star: notifytemplate.Message{
+			Body: "{{ range .Stars }}{{ .StarredBy }}→{{ .Repository }} {{ end }}",
+		},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	n := New(true)
+	n.SetAccount("work")
+	n.SetTemplates(templates)
+
+	entry := cache.CacheEntry{
+		Repository: "owner/repo",
+		Title:      "Fix the login page",
+		Reason:     "review_requested",
+		Type:       "PullRequest",
+		UpdatedAt:  time.Now().Add(-5 * time.Minute),
+		Subject:    &cache.SubjectState{State: "MERGED"},
+	}
+	if got := n.formatTitle(entry); got != "owner/repo (work)" {
+		t.Errorf("Unexpected single title: %q", got)
+	}
+	if got := n.formatMessage(entry); got != "Review requested Pull request: Fix the l… [merged] 5m" {
+		t.Errorf("Unexpected single body: %q", got)
+	}
+
+	entries := []cache.CacheEntry{{Repository: "a/one"}, {Repository: "b/two"}, {Repository: "b/two"}}
+	if got := n.formatBulkTitle(entries); got != "3 for GitHub (work)" {
+		t.Errorf("Unexpected bulk title: %q", got)
+	}
+	if got := n.formatBulkMessage(entries); got != "b/two=2 a/one=1" {
+		t.Errorf("Unexpected bulk body: %q", got)
+	}
+
+	stars := []cache.StarEvent{{StarredBy: "octocat", Repository: "me/tool"}}
+	if got := n.formatStarBulkMessage(stars); got != "octocat→me/tool" {
+		t.Errorf("Unexpected star body: %q", got)
+	}
+	// Without a star title template the built-in title is kept
+	if got := n.formatStarTitle(stars); !strings.Contains(got, "New Star! (work)") {
+		t.Errorf("Expected built-in star title, got %q", got)
+	}
+
+	t.Logf("✓ Template render test passed!")
+}
+
+// TestTemplates_FallBack tests that a template failing at render time falls back to the built-in text
+func TestTemplates_FallBack(t *testing.T) {
+	// The sample entry is a mention, so validation does not reach .Subject.State
+	templates, err := notifytemplate.New(notifytemplate.Config{Single: notifytemplate.Message{Body: `{{ if eq .Reason "assign" }}{{ .Subject.State }}{{ else }}{{ .Title }}{{ end }}`}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	n := New(true)
+	n.SetTemplates(templates)
+
+	entry := cache.CacheEntry{Repository: "owner/repo", Title: "Fix login", Reason: "mention"}
+	if got := n.formatMessage(entry); got != "Fix login" {
+		t.Errorf("Expected template output, got %q", got)
+	}
+
+	entry.Reason = "assign"
+	if got := n.formatMessage(entry); got != "Assigned in owner/repo: Fix login" {
+		t.Errorf("Expected built-in text for a failing template, got %q", got)
+	}
+
+	n.SetTemplates(nil)
+	entry.Reason = "mention"
+	if got := n.formatMessage(entry); got != "Mentioned in owner/repo: Fix login" {
+		t.Errorf("Expected built-in text without templates, got %q", got)
+	}
+
+	t.Logf("✓ Template fallback test passed!")
+}
@@ -0,0 +1,179 @@
+// Package notifytemplate parses the configured desktop notification text. It
+// is kept apart from the notifier so config can validate templates without
+// pulling in D-Bus.
+package notifytemplate
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/catalog"
+	"github.com/bnema/gh-notify/internal/format"
+	"github.com/bnema/gh-notify/internal/logger"
+)
+
+// Config holds Go text/template strings that replace the built-in
+// notification text. Empty strings keep the built-in text.
+type Config struct {
+	Single Message `yaml:"single"` // One notification, rendered with EntryData
+	Bulk   Message `yaml:"bulk"`   // Several notifications, rendered with BulkData
+	Star   Message `yaml:"star"`   // One or more star events, rendered with StarData
+}
+
+// Message is the title and body of one kind of notification
+type Message struct {
+	Title string `yaml:"title"`
+	Body  string `yaml:"body"`
+}
+
+// EntryData is what single notification templates are rendered with. All
+// CacheEntry fields are available directly, e.g. {{ .Title }}.
+type EntryData struct {
+	cache.CacheEntry
+	App string // "GitHub", or "GitHub (account)" when several accounts are polled
+}
+
+// BulkData is what bulk notification templates are rendered with
+type BulkData struct {
+	App          string
+	Account      string
+	Count        int
+	Entries      []cache.CacheEntry
+	Repositories []RepositoryCount // Most notifications first
+}
+
+// RepositoryCount is the number of notifications of one repository
+type RepositoryCount struct {
+	Repository string
+	Count      int
+}
+
+// StarData is what star notification templates are rendered with
+type StarData struct {
+	App     string
+	Account string
+	Count   int
+	Stars   []cache.StarEvent
+}
+
+// templateFuncs are the helpers available to notification templates
+var templateFuncs = template.FuncMap{
+	"reason":      func(reason string) string { return catalog.Reason(reason).Label },
+	"subjectType": func(subjectType string) string { return catalog.Type(subjectType).Label },
+	"icon":        catalog.Icon,
+	"state":       func(s *cache.SubjectState) string { return s.Label() },
+	"age":         func(t time.Time) string { return format.Age(time.Since(t)) },
+	// The length comes first so it works in pipelines: {{ .Title | truncate 40 }}
+	"truncate": func(length int, s string) string { return format.Truncate(s, length) },
+}
+
+// Templates are the parsed notification templates. The zero value, like an
+// empty template, renders the built-in text.
+type Templates struct {
+	Single, Bulk, Star Parsed
+}
+
+// Parsed is the parsed title and body of one kind of notification
+type Parsed struct {
+	title, body *template.Template
+}
+
+// New parses the configured templates and renders each one with sample
+// data, so unknown fields and functions are reported at startup
+func New(config Config) (*Templates, error) {
+	sample := cache.CacheEntry{Repository: "owner/repo", Reason: "mention", Type: "Issue", UpdatedAt: time.Now()}
+
+	t := &Templates{}
+	if err := t.Single.parse("single", config.Single, EntryData{CacheEntry: sample}); err != nil {
+		return nil, err
+	}
+	if err := t.Bulk.parse("bulk", config.Bulk, BulkData{Count: 1, Entries: []cache.CacheEntry{sample}}); err != nil {
+		return nil, err
+	}
+	if err := t.Star.parse("star", config.Star, StarData{Count: 1, Stars: []cache.StarEvent{{Repository: "owner/repo"}}}); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Title renders the title with data. It returns false when there is no
+// title template or it fails, so the caller falls back to the built-in text.
+func (p Parsed) Title(data interface{}) (string, bool) {
+	return render(p.title, data)
+}
+
+// Body renders the body with data, like Title
+func (p Parsed) Body(data interface{}) (string, bool) {
+	return render(p.body, data)
+}
+
+// parse parses the title and body of config, checking each against sample
+func (p *Parsed) parse(name string, config Message, sample interface{}) error {
+	var err error
+	if p.title, err = parseTemplate(name+".title", config.Title, sample); err != nil {
+		return err
+	}
+	p.body, err = parseTemplate(name+".body", config.Body, sample)
+	return err
+}
+
+// parseTemplate parses text and executes it with sample. Empty text yields a
+// nil template.
+func parseTemplate(name, text string, sample interface{}) (*template.Template, error) {
+	if text == "" {
+		return nil, nil
+	}
+
+	tmpl, err := template.New(name).Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	if err := tmpl.Execute(io.Discard, sample); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+
+	return tmpl, nil
+}
+
+// render executes tmpl with data. It returns false when tmpl is nil or
+// fails.
+func render(tmpl *template.Template, data interface{}) (string, bool) {
+	if tmpl == nil {
+		return "", false
+	}
+
+	var text strings.Builder
+	if err := tmpl.Execute(&text, data); err != nil {
+		logger.Warn().Err(err).Str("template", tmpl.Name()).Msg("Notification template failed, using built-in text")
+		return "", false
+	}
+
+	return strings.TrimSpace(text.String()), true
+}
+
+// RepositoryCounts counts entries per repository, most notifications first
+func RepositoryCounts(entries []cache.CacheEntry) []RepositoryCount {
+	index := make(map[string]int)
+	var counts []RepositoryCount
+	for _, entry := range entries {
+		i, ok := index[entry.Repository]
+		if !ok {
+			i = len(counts)
+			index[entry.Repository] = i
+			counts = append(counts, RepositoryCount{Repository: entry.Repository})
+		}
+		counts[i].Count++
+	}
+
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Count > counts[j].Count
+	})
+
+	return counts
+}
@@ -0,0 +1,30 @@
+package notifytemplate
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestNew_Errors tests that broken templates are reported with their name
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "syntax", config: Config{Single: Message{Title: "{{ .Title"}}, wantErr: "single.title: template: single.title:1: unclosed action"},
+		{name: "unknown field", config: Config{Bulk: Message{Body: "{{ .Total }}"}}, wantErr: "bulk.body: template: bulk.body:1:3: executing \"bulk.body\" at <.Total>: can't evaluate field Total"},
+		{name: "unknown function", config: Config{Star: Message{Title: "{{ shout .Count }}"}}, wantErr: "star.title: template: star.title:1: function \"shout\" not defined"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.config)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Logf("✓ Template validation test passed!")
+}
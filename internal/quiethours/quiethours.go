@@ -0,0 +1,133 @@
+// Package quiethours decides whether desktop alerts are suppressed by a
+// do-not-disturb schedule from the config file.
+package quiethours
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Window is a recurring quiet period in local time. Start and End use 24-hour
+// HH:MM; an End before Start runs past midnight into the next day, and End may
+// be 24:00. Days lists the days the window starts on (mon..sun, weekdays or
+// weekends); empty means every day.
+type Window struct {
+	Days  []string `yaml:"days"`
+	Start string   `yaml:"start"`
+	End   string   `yaml:"end"`
+}
+
+// Schedule reports whether a time falls inside any quiet window
+type Schedule struct {
+	windows []window
+}
+
+type window struct {
+	days  [7]bool // Indexed by time.Weekday
+	start int     // Minutes since midnight
+	end   int
+}
+
+var dayNames = map[string][]time.Weekday{
+	"sun":      {time.Sunday},
+	"mon":      {time.Monday},
+	"tue":      {time.Tuesday},
+	"wed":      {time.Wednesday},
+	"thu":      {time.Thursday},
+	"fri":      {time.Friday},
+	"sat":      {time.Saturday},
+	"weekdays": {time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+	"weekends": {time.Saturday, time.Sunday},
+}
+
+// New validates and compiles quiet windows. A nil or empty list yields a
+// schedule that is never active.
+func New(windows []Window) (*Schedule, error) {
+	schedule := &Schedule{}
+
+	for i, w := range windows {
+		start, err := parseClock(w.Start)
+		if err != nil {
+			return nil, fmt.Errorf("window #%d: invalid start: %w", i+1, err)
+		}
+		end, err := parseClock(w.End)
+		if err != nil {
+			return nil, fmt.Errorf("window #%d: invalid end: %w", i+1, err)
+		}
+		if start == end {
+			return nil, fmt.Errorf("window #%d: start and end must differ", i+1)
+		}
+
+		compiled := window{start: start, end: end}
+		if len(w.Days) == 0 {
+			compiled.days = [7]bool{true, true, true, true, true, true, true}
+		}
+		for _, name := range w.Days {
+			days, ok := dayNames[strings.ToLower(name)]
+			if !ok {
+				return nil, fmt.Errorf("window #%d: unknown day %q", i+1, name)
+			}
+			for _, day := range days {
+				compiled.days[day] = true
+			}
+		}
+
+		schedule.windows = append(schedule.windows, compiled)
+	}
+
+	return schedule, nil
+}
+
+// Active reports whether t falls inside a quiet window, using t's location.
+// A nil Schedule is never active.
+func (s *Schedule) Active(t time.Time) bool {
+	if s == nil {
+		return false
+	}
+
+	minute := t.Hour()*60 + t.Minute()
+	today := t.Weekday()
+	yesterday := (today + 6) % 7
+
+	for _, w := range s.windows {
+		if w.start < w.end {
+			if w.days[today] && minute >= w.start && minute < w.end {
+				return true
+			}
+			continue
+		}
+
+		// Overnight: the evening part starts today, the morning part started yesterday
+		if (w.days[today] && minute >= w.start) || (w.days[yesterday] && minute < w.end) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseClock converts HH:MM to minutes since midnight, accepting 24:00
+func parseClock(value string) (int, error) {
+	hours, minutes, ok := strings.Cut(value, ":")
+	if !ok {
+		return 0, fmt.Errorf("%q is not HH:MM", value)
+	}
+
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", value)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil || len(minutes) != 2 {
+		return 0, fmt.Errorf("%q is not HH:MM", value)
+	}
+
+	total := h*60 + m
+	if h < 0 || m < 0 || m > 59 || total > 24*60 {
+		return 0, fmt.Errorf("%q is out of range", value)
+	}
+
+	return total, nil
+}
@@ -0,0 +1,90 @@
+package quiethours
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNew_Validation tests that invalid windows are rejected
+func TestNew_Validation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		window  Window
+		wantErr string
+	}{
+		{name: "valid overnight", window: Window{Days: []string{"weekdays"}, Start: "22:00", End: "07:00"}},
+		{name: "valid full day", window: Window{Days: []string{"sat", "sun"}, Start: "00:00", End: "24:00"}},
+		{name: "bad start", window: Window{Start: "10pm", End: "07:00"}, wantErr: "invalid start"},
+		{name: "bad minutes", window: Window{Start: "22:60", End: "07:00"}, wantErr: "invalid start"},
+		{name: "past midnight", window: Window{Start: "22:00", End: "24:30"}, wantErr: "invalid end"},
+		{name: "empty window", window: Window{Start: "08:00", End: "08:00"}, wantErr: "must differ"},
+		{name: "unknown day", window: Window{Days: []string{"funday"}, Start: "08:00", End: "09:00"}, wantErr: "unknown day"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New([]Window{tc.window})
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	t.Logf("✓ Window validation test passed!")
+}
+
+// TestSchedule_Active tests same-day, overnight and weekend windows
+func TestSchedule_Active(t *testing.T) {
+	schedule, err := New([]Window{
+		{Days: []string{"weekdays"}, Start: "22:00", End: "07:00"},
+		{Days: []string{"weekends"}, Start: "00:00", End: "24:00"},
+		{Days: []string{"wed"}, Start: "12:00", End: "13:00"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// 2025-01-06 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 1, 6+day, hour, minute, 0, 0, time.Local)
+	}
+
+	testCases := []struct {
+		name     string
+		time     time.Time
+		expected bool
+	}{
+		{name: "monday evening", time: at(0, 22, 30), expected: true},
+		{name: "monday before quiet", time: at(0, 21, 59), expected: false},
+		{name: "tuesday morning after monday night", time: at(1, 6, 59), expected: true},
+		{name: "tuesday at end", time: at(1, 7, 0), expected: false},
+		{name: "monday morning after sunday", time: at(0, 6, 0), expected: false},
+		{name: "saturday morning after friday night", time: at(5, 6, 0), expected: true},
+		{name: "saturday afternoon", time: at(5, 15, 0), expected: true},
+		{name: "wednesday lunch", time: at(2, 12, 30), expected: true},
+		{name: "thursday lunch", time: at(3, 12, 30), expected: false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := schedule.Active(tc.time); got != tc.expected {
+				t.Errorf("Active(%v) = %v, want %v", tc.time, got, tc.expected)
+			}
+		})
+	}
+
+	// A nil schedule is never active
+	var none *Schedule
+	if none.Active(at(5, 15, 0)) {
+		t.Error("Expected nil schedule to be inactive")
+	}
+
+	t.Logf("✓ Schedule test passed!")
+}
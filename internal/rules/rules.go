@@ -0,0 +1,149 @@
+// Package rules filters and annotates notifications with user-defined rules
+// from the config file.
+package rules
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/bnema/gh-notify/internal/cache"
+)
+
+// Rule actions
+const (
+	ActionDrop    = "drop"    // Discard the notification entirely
+	ActionSilent  = "silent"  // Keep it cached and listed, but never alert
+	ActionUrgency = "urgency" // Alert with the rule's urgency
+)
+
+// Rule matches notifications and applies an action to them. Empty match
+// fields match anything; a notification must match every non-empty field.
+type Rule struct {
+	Name       string `yaml:"name"`
+	Repository string `yaml:"repository"` // Glob on owner/repo, e.g. "my-org/*"
+	Reason     string `yaml:"reason"`     // e.g. subscribed, review_requested
+	Type       string `yaml:"type"`       // Subject type, e.g. PullRequest, Release
+	Title      string `yaml:"title"`      // Regular expression on the subject title
+	Action     string `yaml:"action"`
+	Urgency    string `yaml:"urgency"` // low, normal or critical, for the urgency action
+}
+
+// Engine applies rules in order; the first matching rule wins
+type Engine struct {
+	rules []compiledRule
+}
+
+type compiledRule struct {
+	Rule
+	title *regexp.Regexp
+}
+
+// New validates and compiles rules. A nil or empty list yields an engine that
+// keeps every notification, though Apply still clears the annotations of
+// earlier rules.
+func New(rules []Rule) (*Engine, error) {
+	engine := &Engine{}
+
+	for i, rule := range rules {
+		label := rule.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+
+		switch rule.Action {
+		case ActionDrop, ActionSilent:
+		case ActionUrgency:
+			switch rule.Urgency {
+			case "low", "normal", "critical":
+			default:
+				return nil, fmt.Errorf("rule %s: urgency must be one of low, normal, critical (got %q)", label, rule.Urgency)
+			}
+		default:
+			return nil, fmt.Errorf("rule %s: action must be one of drop, silent, urgency (got %q)", label, rule.Action)
+		}
+
+		if _, err := path.Match(rule.Repository, ""); err != nil {
+			return nil, fmt.Errorf("rule %s: invalid repository pattern %q: %w", label, rule.Repository, err)
+		}
+
+		compiled := compiledRule{Rule: rule}
+		if rule.Title != "" {
+			title, err := regexp.Compile(rule.Title)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: invalid title pattern: %w", label, err)
+			}
+			compiled.title = title
+		}
+
+		engine.rules = append(engine.rules, compiled)
+	}
+
+	return engine, nil
+}
+
+// Match returns the first rule matching the notification, or nil. A nil
+// Engine matches nothing.
+func (e *Engine) Match(entry cache.CacheEntry) *Rule {
+	if e == nil {
+		return nil
+	}
+
+	for i := range e.rules {
+		if e.rules[i].matches(entry) {
+			return &e.rules[i].Rule
+		}
+	}
+	return nil
+}
+
+// Apply drops notifications matched by drop rules and marks the rest as
+// silent or with an urgency override. Annotations from a previous Apply are
+// reset first, so cached entries follow the current rules.
+func (e *Engine) Apply(entries []cache.CacheEntry) []cache.CacheEntry {
+	var kept []cache.CacheEntry
+
+	for _, entry := range entries {
+		entry.Silent = false
+		entry.Urgency = ""
+
+		if rule := e.Match(entry); rule != nil {
+			switch rule.Action {
+			case ActionDrop:
+				continue
+			case ActionSilent:
+				entry.Silent = true
+			case ActionUrgency:
+				entry.Urgency = rule.Urgency
+			}
+		}
+
+		kept = append(kept, entry)
+	}
+
+	return kept
+}
+
+func (r *compiledRule) matches(entry cache.CacheEntry) bool {
+	if r.Repository != "" {
+		// Pattern errors were rejected by New
+		if ok, _ := path.Match(strings.ToLower(r.Repository), strings.ToLower(entry.Repository)); !ok {
+			return false
+		}
+	}
+
+	if r.Reason != "" && !strings.EqualFold(r.Reason, entry.Reason) {
+		return false
+	}
+
+	if r.Type != "" && !strings.EqualFold(r.Type, entry.Type) {
+		return false
+	}
+
+	if r.title != nil && !r.title.MatchString(entry.Title) {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,148 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/bnema/gh-notify/internal/cache"
+)
+
+// TestNew_Validation tests that invalid rules are rejected
+func TestNew_Validation(t *testing.T) {
+	testCases := []struct {
+		name    string
+		rule    Rule
+		wantErr string
+	}{
+		{name: "valid drop", rule: Rule{Reason: "subscribed", Action: ActionDrop}},
+		{name: "valid urgency", rule: Rule{Type: "Release", Action: ActionUrgency, Urgency: "low"}},
+		{name: "unknown action", rule: Rule{Action: "hide"}, wantErr: "action must be one of"},
+		{name: "missing urgency", rule: Rule{Action: ActionUrgency}, wantErr: "urgency must be one of"},
+		{name: "bad glob", rule: Rule{Repository: "org/[", Action: ActionDrop}, wantErr: "invalid repository pattern"},
+		{name: "bad regex", rule: Rule{Title: "(", Action: ActionDrop}, wantErr: "invalid title pattern"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := New([]Rule{tc.rule})
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tc.wantErr, err)
+			}
+		})
+	}
+
+	t.Logf("✓ Rule validation test passed!")
+}
+
+// TestEngine_Match tests matching on repository, reason, type and title
+func TestEngine_Match(t *testing.T) {
+	engine, err := New([]Rule{
+		{Name: "bots", Title: `^(chore|build)\(deps\)`, Action: ActionDrop},
+		{Name: "org", Repository: "my-org/*", Reason: "subscribed", Action: ActionSilent},
+		{Name: "releases", Type: "release", Action: ActionUrgency, Urgency: "low"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		entry    cache.CacheEntry
+		expected string
+	}{
+		{
+			name:     "title regex",
+			entry:    cache.CacheEntry{Repository: "owner/repo", Title: "chore(deps): bump x"},
+			expected: "bots",
+		},
+		{
+			name:     "glob and reason, case-insensitive",
+			entry:    cache.CacheEntry{Repository: "My-Org/tool", Reason: "subscribed"},
+			expected: "org",
+		},
+		{
+			name:     "glob without reason",
+			entry:    cache.CacheEntry{Repository: "my-org/tool", Reason: "mention"},
+			expected: "",
+		},
+		{
+			name:     "glob does not cross owners",
+			entry:    cache.CacheEntry{Repository: "other/my-org", Reason: "subscribed"},
+			expected: "",
+		},
+		{
+			name:     "subject type",
+			entry:    cache.CacheEntry{Repository: "owner/repo", Type: "Release"},
+			expected: "releases",
+		},
+		{
+			name:     "first match wins",
+			entry:    cache.CacheEntry{Repository: "my-org/tool", Reason: "subscribed", Title: "build(deps): bump y"},
+			expected: "bots",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rule := engine.Match(tc.entry)
+			got := ""
+			if rule != nil {
+				got = rule.Name
+			}
+			if got != tc.expected {
+				t.Errorf("Expected rule %q, got %q", tc.expected, got)
+			}
+		})
+	}
+
+	t.Logf("✓ Rule matching test passed!")
+}
+
+// TestEngine_Apply tests the drop, silent and urgency actions
+func TestEngine_Apply(t *testing.T) {
+	engine, err := New([]Rule{
+		{Reason: "ci_activity", Action: ActionDrop},
+		{Reason: "subscribed", Action: ActionSilent},
+		{Reason: "security_alert", Action: ActionUrgency, Urgency: "critical"},
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	entries := []cache.CacheEntry{
+		{ID: "1", Reason: "ci_activity"},
+		{ID: "2", Reason: "subscribed"},
+		{ID: "3", Reason: "security_alert"},
+		// Stale annotations from an older rule set are reset
+		{ID: "4", Reason: "mention", Silent: true, Urgency: "low"},
+	}
+
+	result := engine.Apply(entries)
+
+	if len(result) != 3 {
+		t.Fatalf("Expected 3 notifications after drop, got %d", len(result))
+	}
+	if result[0].ID != "2" || !result[0].Silent {
+		t.Errorf("Expected '2' to be silent, got %+v", result[0])
+	}
+	if result[1].ID != "3" || result[1].Urgency != "critical" {
+		t.Errorf("Expected '3' to be critical, got %+v", result[1])
+	}
+	if result[2].Silent || result[2].Urgency != "" {
+		t.Errorf("Expected '4' annotations to be reset, got %+v", result[2])
+	}
+
+	// A nil engine keeps every notification
+	var none *Engine
+	if got := none.Apply(entries[:1]); len(got) != 1 {
+		t.Errorf("Expected nil engine to keep notifications, got %d", len(got))
+	}
+
+	t.Logf("✓ Rule actions test passed!")
+}
@@ -17,9 +17,11 @@ Wants=network-online.target
 
 [Service]
 Type=oneshot
-ExecStart={{.BinaryPath}} sync
+ExecStart={{quote .BinaryPath}}{{if .ConfigPath}} --config {{quote .ConfigPath}}{{end}}{{if .Hostname}} --hostname {{quote .Hostname}}{{end}} sync
 StandardOutput=journal
 StandardError=journal
+# Keep notification helpers alive after sync exits so their buttons still work
+KillMode=process
 # Restart on failure with delay
 Restart=on-failure
 RestartSec=30s
@@ -40,12 +42,24 @@ Persistent=true
 [Install]
 WantedBy=timers.target`
 
+// templateFuncs are available to the unit templates
+var templateFuncs = template.FuncMap{"quote": quoteArg}
+
+// quoteArg quotes a command line argument for ExecStart, so paths with spaces
+// stay one argument and systemd expands no specifiers or variables in them
+func quoteArg(arg string) string {
+	arg = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "%", "%%", "$", "$$").Replace(arg)
+	return `"` + arg + `"`
+}
+
 type SystemdManager struct {
 	serviceDir string
 }
 
 type TemplateData struct {
 	BinaryPath string
+	ConfigPath string
+	Hostname   string
 	Interval   string
 }
 
@@ -62,7 +76,9 @@ func NewSystemdManager() (*SystemdManager, error) {
 	}, nil
 }
 
-func (sm *SystemdManager) Install(interval time.Duration, dryRun bool) error {
+// Install writes and enables the service and timer. A non-empty configPath or
+// hostname is passed to the sync command via --config or --hostname.
+func (sm *SystemdManager) Install(interval time.Duration, configPath, hostname string, dryRun bool) error {
 	// Get binary path
 	binaryPath, err := os.Executable()
 	if err != nil {
@@ -72,6 +88,8 @@ func (sm *SystemdManager) Install(interval time.Duration, dryRun bool) error {
 	// Prepare template data
 	data := TemplateData{
 		BinaryPath: binaryPath,
+		ConfigPath: configPath,
+		Hostname:   hostname,
 		Interval:   formatDuration(interval),
 	}
 
@@ -140,7 +158,7 @@ func (sm *SystemdManager) Status() (string, error) {
 }
 
 func (sm *SystemdManager) writeServiceFile(data TemplateData) error {
-	tmpl, err := template.New("service").Parse(serviceTemplate)
+	tmpl, err := template.New("service").Funcs(templateFuncs).Parse(serviceTemplate)
 	if err != nil {
 		return fmt.Errorf("failed to parse service template: %w", err)
 	}
@@ -202,11 +220,17 @@ func (sm *SystemdManager) showDryRun(data TemplateData) error {
 	fmt.Println("=== Dry Run: Service Installation ===")
 	fmt.Printf("Service directory: %s\n", sm.serviceDir)
 	fmt.Printf("Binary path: %s\n", data.BinaryPath)
+	if data.ConfigPath != "" {
+		fmt.Printf("Config file: %s\n", data.ConfigPath)
+	}
+	if data.Hostname != "" {
+		fmt.Printf("Hostname: %s\n", data.Hostname)
+	}
 	fmt.Printf("Sync interval: %s\n", data.Interval)
 	fmt.Println()
 
 	fmt.Println("--- gh-notify.service ---")
-	tmpl, _ := template.New("service").Parse(serviceTemplate)
+	tmpl, _ := template.New("service").Funcs(templateFuncs).Parse(serviceTemplate)
 	if err := tmpl.Execute(os.Stdout, data); err != nil {
 		fmt.Printf("Error displaying service template: %v\n", err)
 	}
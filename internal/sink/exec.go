@@ -0,0 +1,102 @@
+package sink
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxOutputInError bounds the command output quoted in delivery errors
+const maxOutputInError = 200
+
+// execSink runs a shell command per delivery. The event (or, when batched,
+// the array of events) is written to stdin as JSON, and key fields are set as
+// GH_NOTIFY_* environment variables.
+type execSink struct {
+	command string
+	batch   bool
+	timeout time.Duration
+}
+
+func (s *execSink) Send(events []Event) error {
+	var payload []byte
+	var err error
+	if s.batch {
+		payload, err = json.Marshal(events)
+	} else {
+		payload, err = json.Marshal(events[0])
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", s.command)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(), eventEnv(events, s.batch)...)
+	// Background children of the command must not hold its output open forever
+	cmd.WaitDelay = time.Second
+
+	output, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return fmt.Errorf("command timed out after %v", s.timeout)
+	}
+	if err != nil {
+		out := strings.TrimSpace(string(output))
+		if len(out) > maxOutputInError {
+			out = out[:maxOutputInError] + "..."
+		}
+		return fmt.Errorf("command failed: %w: %s", err, out)
+	}
+
+	return nil
+}
+
+// eventEnv returns the GH_NOTIFY_* variables describing a delivery. Batches
+// only carry their size and account; the events themselves are on stdin.
+func eventEnv(events []Event, batch bool) []string {
+	if batch {
+		env := []string{"GH_NOTIFY_COUNT=" + strconv.Itoa(len(events))}
+		if len(events) > 0 && events[0].Account != "" {
+			env = append(env, "GH_NOTIFY_ACCOUNT="+events[0].Account)
+		}
+		return env
+	}
+
+	event := events[0]
+	vars := map[string]string{
+		"GH_NOTIFY_KIND":    event.Kind,
+		"GH_NOTIFY_ACCOUNT": event.Account,
+	}
+
+	if n := event.Notification; n != nil {
+		vars["GH_NOTIFY_ID"] = n.ID
+		vars["GH_NOTIFY_REPO"] = n.Repository
+		vars["GH_NOTIFY_REASON"] = n.Reason
+		vars["GH_NOTIFY_TYPE"] = n.Type
+		vars["GH_NOTIFY_TITLE"] = n.Title
+		vars["GH_NOTIFY_URL"] = n.WebURL
+		vars["GH_NOTIFY_STATE"] = n.Subject.Label()
+	}
+	if star := event.Star; star != nil {
+		vars["GH_NOTIFY_REPO"] = star.Repository
+		vars["GH_NOTIFY_STARRED_BY"] = star.StarredBy
+	}
+
+	env := make([]string, 0, len(vars))
+	for key, value := range vars {
+		if value != "" {
+			env = append(env, key+"="+value)
+		}
+	}
+	return env
+}
@@ -0,0 +1,162 @@
+// Package sink forwards new notifications and star events to user-configured
+// destinations, such as a command or a webhook, alongside the desktop alerts.
+package sink
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+	"github.com/bnema/gh-notify/internal/logger"
+)
+
+// Sink types
+const (
+	TypeExec    = "exec"    // Run a command with the event as JSON on stdin
+	TypeWebhook = "webhook" // POST the events to a URL
+)
+
+// Event kinds
+const (
+	KindNotification = "notification"
+	KindStar         = "star"
+)
+
+// DefaultTimeout bounds one delivery when a sink sets no timeout
+const DefaultTimeout = 10 * time.Second
+
+// Config is one sink from the config file
+type Config struct {
+	Name    string        `yaml:"name"`
+	Type    string        `yaml:"type"`
+	Command string        `yaml:"command"` // exec: run with sh -c
+	Batch   bool          `yaml:"batch"`   // Deliver all events of a sync at once instead of one by one; webhooks always do
+	Timeout time.Duration `yaml:"timeout"` // Per delivery attempt; defaults to DefaultTimeout
+
+	URL      string            `yaml:"url"`      // webhook: where to send the events
+	Method   string            `yaml:"method"`   // webhook: HTTP method, POST by default
+	Preset   string            `yaml:"preset"`   // webhook: slack, discord, matrix or custom
+	Template string            `yaml:"template"` // webhook: Go template for the custom preset's body
+	Headers  map[string]string `yaml:"headers"`  // webhook: extra request headers
+	Retries  *int              `yaml:"retries"`  // webhook: retries after a failure; defaults to DefaultRetries
+}
+
+// Event is a new notification or star event
+type Event struct {
+	Kind         string            `json:"kind"`
+	Account      string            `json:"account,omitempty"`
+	Notification *cache.CacheEntry `json:"notification,omitempty"`
+	Star         *cache.StarEvent  `json:"star,omitempty"`
+}
+
+// Sink delivers events to one destination
+type Sink interface {
+	// Send delivers one event, or a whole batch when the sink is batched
+	Send(events []Event) error
+}
+
+type configuredSink struct {
+	Config
+	sink Sink
+}
+
+// Set holds the configured sinks
+type Set struct {
+	sinks []configuredSink
+}
+
+// New validates the sink configs and builds their sinks. A nil or empty list
+// yields a set that delivers nothing.
+func New(configs []Config) (*Set, error) {
+	set := &Set{}
+
+	for i, config := range configs {
+		label := config.Name
+		if label == "" {
+			label = fmt.Sprintf("#%d", i+1)
+		}
+		config.Name = label
+
+		if config.Timeout < 0 {
+			return nil, fmt.Errorf("sink %s: timeout must not be negative", label)
+		}
+		if config.Timeout == 0 {
+			config.Timeout = DefaultTimeout
+		}
+
+		var sink Sink
+		switch config.Type {
+		case TypeExec:
+			if config.Command == "" {
+				return nil, fmt.Errorf("sink %s: exec sinks need a command", label)
+			}
+			sink = &execSink{command: config.Command, batch: config.Batch, timeout: config.Timeout}
+		case TypeWebhook:
+			webhook, err := newWebhookSink(config)
+			if err != nil {
+				return nil, fmt.Errorf("sink %s: %w", label, err)
+			}
+			config.Batch = true
+			sink = webhook
+		default:
+			return nil, fmt.Errorf("sink %s: type must be %s or %s (got %q)", label, TypeExec, TypeWebhook, config.Type)
+		}
+
+		set.sinks = append(set.sinks, configuredSink{Config: config, sink: sink})
+	}
+
+	return set, nil
+}
+
+// Deliver sends the new notifications and stars of one account to every
+// sink. Failures are logged and never returned, so a broken sink cannot stop
+// a sync. A nil set delivers nothing.
+func (s *Set) Deliver(account string, notifications []cache.CacheEntry, stars []cache.StarEvent) {
+	if s == nil || len(s.sinks) == 0 {
+		return
+	}
+
+	events := Events(account, notifications, stars)
+	if len(events) == 0 {
+		return
+	}
+
+	for _, cs := range s.sinks {
+		batches := [][]Event{events}
+		if !cs.Batch {
+			batches = make([][]Event, len(events))
+			for i := range events {
+				batches[i] = events[i : i+1]
+			}
+		}
+
+		for _, batch := range batches {
+			start := time.Now()
+			if err := cs.sink.Send(batch); err != nil {
+				logger.Warn().
+					Err(err).
+					Str("sink", cs.Name).
+					Int("events", len(batch)).
+					Msg("Sink delivery failed")
+				continue
+			}
+			logger.Debug().
+				Str("sink", cs.Name).
+				Int("events", len(batch)).
+				Dur("duration", time.Since(start)).
+				Msg("Sink delivery succeeded")
+		}
+	}
+}
+
+// Events converts notifications and stars to sink events
+func Events(account string, notifications []cache.CacheEntry, stars []cache.StarEvent) []Event {
+	events := make([]Event, 0, len(notifications)+len(stars))
+	for i := range notifications {
+		events = append(events, Event{Kind: KindNotification, Account: account, Notification: &notifications[i]})
+	}
+	for i := range stars {
+		events = append(events, Event{Kind: KindStar, Account: account, Star: &stars[i]})
+	}
+	return events
+}
@@ -0,0 +1,137 @@
+package sink
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bnema/gh-notify/internal/cache"
+)
+
+// TestNew tests sink config validation
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		configs []Config
+		wantErr string
+	}{
+		{name: "no sinks"},
+		{name: "exec sink", configs: []Config{{Type: TypeExec, Command: "true"}}},
+		{name: "missing command", configs: []Config{{Name: "script", Type: TypeExec}}, wantErr: "sink script: exec sinks need a command"},
+		{name: "unknown type", configs: []Config{{Type: "pager"}}, wantErr: "sink #1: type must be"},
+		{name: "negative timeout", configs: []Config{{Type: TypeExec, Command: "true", Timeout: -time.Second}}, wantErr: "timeout must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := New(tt.configs)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Logf("✓ Sink config validation test passed!")
+}
+
+// TestExecSink_PerEvent tests that each event runs the command with JSON on stdin and env vars
+func TestExecSink_PerEvent(t *testing.T) {
+	dir := t.TempDir()
+	set, err := New([]Config{{
+		Type:    TypeExec,
+		Command: `cat > "` + dir + `/$GH_NOTIFY_ID.json"; echo "$GH_NOTIFY_REPO $GH_NOTIFY_REASON $GH_NOTIFY_URL" > "` + dir + `/$GH_NOTIFY_ID.env"`,
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	set.Deliver("work", []cache.CacheEntry{
+		{ID: "1", Repository: "owner/repo", Reason: "mention", WebURL: "https://github.com/owner/repo/issues/1"},
+		{ID: "2", Repository: "owner/tool", Reason: "assign"},
+	}, nil)
+
+	env, err := os.ReadFile(filepath.Join(dir, "1.env"))
+	if err != nil {
+		t.Fatalf("Expected command output for event 1: %v", err)
+	}
+	if strings.TrimSpace(string(env)) != "owner/repo mention https://github.com/owner/repo/issues/1" {
+		t.Errorf("Unexpected env vars: %q", env)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "2.json"))
+	if err != nil {
+		t.Fatalf("Expected command output for event 2: %v", err)
+	}
+	var event Event
+	if err := json.Unmarshal(data, &event); err != nil {
+		t.Fatalf("Expected a JSON event on stdin: %v", err)
+	}
+	if event.Kind != KindNotification || event.Account != "work" || event.Notification.Repository != "owner/tool" {
+		t.Errorf("Unexpected event: %+v", event)
+	}
+
+	t.Logf("✓ Exec sink per event test passed!")
+}
+
+// TestExecSink_Batch tests that a batched sink runs once with an array of events
+func TestExecSink_Batch(t *testing.T) {
+	out := filepath.Join(t.TempDir(), "batch.json")
+	set, err := New([]Config{{
+		Type:    TypeExec,
+		Command: `cat > "` + out + `"; echo "$GH_NOTIFY_COUNT" >> "` + out + `.count"`,
+		Batch:   true,
+	}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	set.Deliver("", []cache.CacheEntry{{ID: "1"}}, []cache.StarEvent{{ID: "s1", StarredBy: "octocat", Repository: "me/tool"}})
+
+	count, err := os.ReadFile(out + ".count")
+	if err != nil || strings.TrimSpace(string(count)) != "2" {
+		t.Fatalf("Expected one run for 2 events, got %q (%v)", count, err)
+	}
+
+	data, _ := os.ReadFile(out)
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		t.Fatalf("Expected a JSON array on stdin: %v", err)
+	}
+	if len(events) != 2 || events[1].Kind != KindStar || events[1].Star.StarredBy != "octocat" {
+		t.Errorf("Unexpected events: %+v", events)
+	}
+
+	t.Logf("✓ Exec sink batch test passed!")
+}
+
+// TestExecSink_Failures tests that timeouts and failing commands are reported
+func TestExecSink_Failures(t *testing.T) {
+	events := []Event{{Kind: KindNotification, Notification: &cache.CacheEntry{ID: "1"}}}
+
+	slow := &execSink{command: "sleep 5", timeout: 100 * time.Millisecond}
+	start := time.Now()
+	err := slow.Send(events)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("Expected timeout error, got %v", err)
+	}
+	if time.Since(start) > 3*time.Second {
+		t.Errorf("Expected the command to be killed at the timeout, took %v", time.Since(start))
+	}
+
+	failing := &execSink{command: "echo boom >&2; exit 3", timeout: time.Second}
+	err = failing.Send(events)
+	if err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Errorf("Expected failure with command output, got %v", err)
+	}
+
+	t.Logf("✓ Exec sink failure test passed!")
+}
@@ -0,0 +1,291 @@
+package sink
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bnema/gh-notify/internal/catalog"
+	"github.com/bnema/gh-notify/internal/logger"
+)
+
+// Webhook payload presets
+const (
+	PresetSlack   = "slack"   // {"text": ...} with Slack mrkdwn links
+	PresetDiscord = "discord" // {"content": ...} with Markdown links
+	PresetMatrix  = "matrix"  // m.room.message with plain and HTML bodies
+	PresetCustom  = "custom"  // Body rendered from Template
+)
+
+const (
+	// DefaultRetries is the number of retries after a failed webhook request
+	DefaultRetries = 3
+	// webhookBackoff is the delay before the first retry; it doubles after each
+	webhookBackoff = time.Second
+	// webhookMaxRetryAfter caps how long a Retry-After header can delay a retry
+	webhookMaxRetryAfter = time.Minute
+	// discordMaxContent is Discord's message length limit
+	discordMaxContent = 2000
+)
+
+// webhookSink sends each batch of events in one HTTP request
+type webhookSink struct {
+	url      string
+	method   string
+	preset   string
+	template *template.Template
+	headers  map[string]string
+	retries  int
+	backoff  time.Duration
+	client   *http.Client
+}
+
+// TemplateData is what a custom webhook template is rendered with
+type TemplateData struct {
+	Account string
+	Count   int
+	Events  []Event
+}
+
+// templateFuncs are available to custom webhook templates
+var templateFuncs = template.FuncMap{
+	// json encodes a value, e.g. {{ json .Notification.Title }} for a quoted string
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+	// summary is the one-line text the presets use for an event
+	"summary": func(e Event) string {
+		return eventText(e)
+	},
+	"reason": func(reason string) string {
+		return catalog.Reason(reason).Label
+	},
+}
+
+// newWebhookSink validates a webhook config and parses its template
+func newWebhookSink(config Config) (*webhookSink, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("webhook sinks need a url")
+	}
+	retries := DefaultRetries
+	if config.Retries != nil {
+		retries = *config.Retries
+	}
+	if retries < 0 {
+		return nil, fmt.Errorf("retries must not be negative")
+	}
+
+	sink := &webhookSink{
+		url:     config.URL,
+		method:  config.Method,
+		preset:  config.Preset,
+		headers: config.Headers,
+		retries: retries,
+		backoff: webhookBackoff,
+		client:  &http.Client{Timeout: config.Timeout},
+	}
+	if sink.method == "" {
+		sink.method = http.MethodPost
+	}
+	if sink.preset == "" {
+		sink.preset = PresetCustom
+		if config.Template == "" {
+			sink.preset = PresetSlack
+		}
+	}
+
+	switch sink.preset {
+	case PresetSlack, PresetDiscord, PresetMatrix:
+		if config.Template != "" {
+			return nil, fmt.Errorf("template is only used with the %s preset", PresetCustom)
+		}
+	case PresetCustom:
+		if config.Template == "" {
+			return nil, fmt.Errorf("the %s preset needs a template", PresetCustom)
+		}
+		tmpl, err := template.New("webhook").Funcs(templateFuncs).Option("missingkey=error").Parse(config.Template)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template: %w", err)
+		}
+		sink.template = tmpl
+	default:
+		return nil, fmt.Errorf("preset must be one of %s, %s, %s, %s (got %q)",
+			PresetSlack, PresetDiscord, PresetMatrix, PresetCustom, sink.preset)
+	}
+
+	return sink, nil
+}
+
+// Send posts the events, retrying network errors, 429 and 5xx responses with
+// exponential backoff, or after the delay the server asks for in Retry-After
+func (s *webhookSink) Send(events []Event) error {
+	body, err := s.payload(events)
+	if err != nil {
+		return err
+	}
+
+	backoff := s.backoff
+	for attempt := 0; ; attempt++ {
+		retryable, wait, err := s.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retryable || attempt >= s.retries {
+			return fmt.Errorf("webhook failed after %d attempts: %w", attempt+1, err)
+		}
+		if wait == 0 {
+			wait = backoff
+		}
+
+		logger.Debug().Err(err).Int("attempt", attempt+1).Dur("backoff", wait).Msg("Webhook delivery failed, retrying")
+		time.Sleep(wait)
+		backoff *= 2
+	}
+}
+
+// post sends one request and reports whether a failure is worth retrying,
+// and after how long if the server said so
+func (s *webhookSink) post(body []byte) (bool, time.Duration, error) {
+	req, err := http.NewRequest(s.method, s.url, bytes.NewReader(body))
+	if err != nil {
+		return false, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range s.headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, 0, nil
+	}
+
+	retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+	return retryable, retryAfter(resp.Header.Get("Retry-After"), time.Now()), fmt.Errorf("HTTP %d", resp.StatusCode)
+}
+
+// retryAfter parses a Retry-After header, in seconds or as an HTTP date, into
+// a delay of at most webhookMaxRetryAfter. It returns 0 when there is none.
+func retryAfter(value string, now time.Time) time.Duration {
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(value); err == nil {
+		wait = time.Duration(seconds) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		wait = date.Sub(now)
+	}
+
+	return max(0, min(wait, webhookMaxRetryAfter))
+}
+
+// payload renders the request body for the sink's preset
+func (s *webhookSink) payload(events []Event) ([]byte, error) {
+	switch s.preset {
+	case PresetSlack:
+		lines := make([]string, len(events))
+		for i, e := range events {
+			lines[i] = "• " + linkedText(e, func(text, url string) string {
+				return fmt.Sprintf("<%s|%s>", url, slackEscape(text))
+			}, slackEscape)
+		}
+		return json.Marshal(map[string]string{"text": strings.Join(lines, "\n")})
+
+	case PresetDiscord:
+		lines := make([]string, len(events))
+		for i, e := range events {
+			lines[i] = "- " + linkedText(e, func(text, url string) string {
+				return fmt.Sprintf("[%s](<%s>)", text, url)
+			}, func(text string) string { return text })
+		}
+		// Discord counts characters, and a cut must not split one
+		content := strings.Join(lines, "\n")
+		if utf8.RuneCountInString(content) > discordMaxContent {
+			content = string([]rune(content)[:discordMaxContent-3]) + "..."
+		}
+		return json.Marshal(map[string]string{"content": content})
+
+	case PresetMatrix:
+		plain := make([]string, len(events))
+		rich := make([]string, len(events))
+		for i, e := range events {
+			plain[i] = eventText(e)
+			rich[i] = "<li>" + linkedText(e, func(text, url string) string {
+				return fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(url), html.EscapeString(text))
+			}, html.EscapeString) + "</li>"
+		}
+		return json.Marshal(map[string]string{
+			"msgtype":        "m.text",
+			"body":           strings.Join(plain, "\n"),
+			"format":         "org.matrix.custom.html",
+			"formatted_body": "<ul>" + strings.Join(rich, "") + "</ul>",
+		})
+	}
+
+	data := TemplateData{Count: len(events), Events: events}
+	if len(events) > 0 {
+		data.Account = events[0].Account
+	}
+
+	var body bytes.Buffer
+	if err := s.template.Execute(&body, data); err != nil {
+		return nil, fmt.Errorf("failed to render template: %w", err)
+	}
+	return body.Bytes(), nil
+}
+
+// eventText describes an event in one line, e.g.
+// "Mentioned in owner/repo: Fix login (https://github.com/...)"
+func eventText(e Event) string {
+	return linkedText(e, func(text, url string) string {
+		return fmt.Sprintf("%s (%s)", text, url)
+	}, func(text string) string { return text })
+}
+
+// linkedText describes an event, linking the title with link when it has a
+// URL. escape is applied to all other text.
+func linkedText(e Event, link func(text, url string) string, escape func(string) string) string {
+	prefix := ""
+	if e.Account != "" {
+		prefix = escape("[" + e.Account + "] ")
+	}
+
+	if star := e.Star; star != nil {
+		return prefix + escape(fmt.Sprintf("%s starred %s", star.StarredBy, star.Repository))
+	}
+
+	n := e.Notification
+	if n == nil {
+		return prefix
+	}
+
+	title := n.Title
+	if state := n.Subject.Label(); state != "" {
+		title += " [" + state + "]"
+	}
+	if n.WebURL != "" {
+		title = link(title, n.WebURL)
+	} else {
+		title = escape(title)
+	}
+
+	return prefix + escape(fmt.Sprintf("%s in %s: ", catalog.Reason(n.Reason).Label, n.Repository)) + title
+}
+
+// slackEscape escapes the characters Slack mrkdwn treats as markup
+func slackEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
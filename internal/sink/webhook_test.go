@@ -0,0 +1,275 @@
+package sink
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/bnema/gh-notify/internal/cache"
+)
+
+// receiver is an httptest webhook endpoint that records request bodies and
+// answers with the queued status codes, then 204
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	bodies   []string
+	headers  []http.Header
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bodies = append(r.bodies, string(body))
+	r.headers = append(r.headers, req.Header.Clone())
+
+	status := http.StatusNoContent
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+// newWebhookSet starts a receiver and builds a set with one webhook sink
+// pointing at it, with retries sped up for the test
+func newWebhookSet(t *testing.T, config Config, statuses ...int) (*Set, *receiver) {
+	t.Helper()
+
+	recv := &receiver{statuses: statuses}
+	server := httptest.NewServer(recv)
+	t.Cleanup(server.Close)
+
+	config.Type = TypeWebhook
+	config.URL = server.URL
+	set, err := New([]Config{config})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	set.sinks[0].sink.(*webhookSink).backoff = time.Millisecond
+
+	return set, recv
+}
+
+var webhookNotifications = []cache.CacheEntry{
+	{ID: "1", Repository: "owner/repo", Reason: "mention", Title: "Fix <login>", WebURL: "https://github.com/owner/repo/issues/1"},
+	{ID: "2", Repository: "owner/tool", Reason: "review_requested", Title: "Add flag"},
+}
+
+var webhookStars = []cache.StarEvent{{StarredBy: "octocat", Repository: "me/tool"}}
+
+// TestNew_Webhook tests webhook sink config validation
+func TestNew_Webhook(t *testing.T) {
+	negative := -1
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr string
+	}{
+		{name: "default preset", config: Config{URL: "http://localhost"}},
+		{name: "custom template", config: Config{URL: "http://localhost", Template: `{"n": {{ .Count }}}`}},
+		{name: "missing url", config: Config{}, wantErr: "webhook sinks need a url"},
+		{name: "unknown preset", config: Config{URL: "http://localhost", Preset: "teams"}, wantErr: "preset must be one of"},
+		{name: "custom without template", config: Config{URL: "http://localhost", Preset: PresetCustom}, wantErr: "needs a template"},
+		{name: "template with preset", config: Config{URL: "http://localhost", Preset: PresetSlack, Template: "x"}, wantErr: "only used with the custom preset"},
+		{name: "invalid template", config: Config{URL: "http://localhost", Template: "{{ .Count"}, wantErr: "invalid template"},
+		{name: "negative retries", config: Config{URL: "http://localhost", Retries: &negative}, wantErr: "retries must not be negative"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Type = TypeWebhook
+			_, err := New([]Config{tt.config})
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("Expected no error, got %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+
+	t.Logf("✓ Webhook config validation test passed!")
+}
+
+// TestWebhookSink_Presets tests that each preset posts one batch with the
+// chat service's JSON shape
+func TestWebhookSink_Presets(t *testing.T) {
+	tests := []struct {
+		preset string
+		key    string
+		want   []string
+	}{
+		{PresetSlack, "text", []string{
+			"• [work] Mentioned in owner/repo: <https://github.com/owner/repo/issues/1|Fix &lt;login&gt;>",
+			"• [work] Review requested in owner/tool: Add flag",
+			"• [work] octocat starred me/tool",
+		}},
+		{PresetDiscord, "content", []string{
+			"- [work] Mentioned in owner/repo: [Fix <login>](<https://github.com/owner/repo/issues/1>)",
+			"- [work] octocat starred me/tool",
+		}},
+		{PresetMatrix, "formatted_body", []string{
+			`<li>[work] Mentioned in owner/repo: <a href="https://github.com/owner/repo/issues/1">Fix &lt;login&gt;</a></li>`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			set, recv := newWebhookSet(t, Config{Preset: tt.preset, Headers: map[string]string{"Authorization": "Bearer token"}})
+			set.Deliver("work", webhookNotifications, webhookStars)
+
+			if len(recv.bodies) != 1 {
+				t.Fatalf("Expected one batched request, got %d", len(recv.bodies))
+			}
+			if got := recv.headers[0].Get("Authorization"); got != "Bearer token" {
+				t.Errorf("Expected configured header, got %q", got)
+			}
+			if got := recv.headers[0].Get("Content-Type"); got != "application/json" {
+				t.Errorf("Expected JSON content type, got %q", got)
+			}
+
+			var payload map[string]string
+			if err := json.Unmarshal([]byte(recv.bodies[0]), &payload); err != nil {
+				t.Fatalf("Expected a JSON body: %v", err)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(payload[tt.key], want) {
+					t.Errorf("Expected %s to contain %q, got %q", tt.key, want, payload[tt.key])
+				}
+			}
+			if tt.preset == PresetMatrix && (payload["msgtype"] != "m.text" || !strings.Contains(payload["body"], "octocat starred me/tool")) {
+				t.Errorf("Unexpected Matrix payload: %v", payload)
+			}
+		})
+	}
+
+	t.Logf("✓ Webhook preset test passed!")
+}
+
+// TestWebhookSink_Template tests custom template bodies
+func TestWebhookSink_Template(t *testing.T) {
+	set, recv := newWebhookSet(t, Config{
+		Template: `{"account": {{ json .Account }}, "count": {{ .Count }}, "titles": [{{ range $i, $e := .Events }}{{ if $i }}, {{ end }}{{ if $e.Notification }}{{ json $e.Notification.Title }}{{ else }}{{ json (summary $e) }}{{ end }}{{ end }}]}`,
+	})
+	set.Deliver("work", webhookNotifications, webhookStars)
+
+	if len(recv.bodies) != 1 {
+		t.Fatalf("Expected one request, got %d", len(recv.bodies))
+	}
+
+	var payload struct {
+		Account string   `json:"account"`
+		Count   int      `json:"count"`
+		Titles  []string `json:"titles"`
+	}
+	if err := json.Unmarshal([]byte(recv.bodies[0]), &payload); err != nil {
+		t.Fatalf("Expected the template to render JSON, got %q: %v", recv.bodies[0], err)
+	}
+	if payload.Account != "work" || payload.Count != 3 {
+		t.Errorf("Unexpected payload: %+v", payload)
+	}
+	if strings.Join(payload.Titles, "|") != "Fix <login>|Add flag|[work] octocat starred me/tool" {
+		t.Errorf("Unexpected titles: %v", payload.Titles)
+	}
+
+	t.Logf("✓ Webhook template test passed!")
+}
+
+// TestWebhookSink_Retries tests that server errors are retried and client
+// errors are not
+func TestWebhookSink_Retries(t *testing.T) {
+	one := 1
+
+	tests := []struct {
+		name      string
+		retries   *int
+		statuses  []int
+		wantCalls int
+		wantErr   string
+	}{
+		{name: "recovers", statuses: []int{500, 429}, wantCalls: 3},
+		{name: "gives up", retries: &one, statuses: []int{502, 503, 504}, wantCalls: 2, wantErr: "webhook failed after 2 attempts: HTTP 503"},
+		{name: "client error", statuses: []int{400}, wantCalls: 1, wantErr: "webhook failed after 1 attempts: HTTP 400"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			set, recv := newWebhookSet(t, Config{Retries: tt.retries}, tt.statuses...)
+
+			err := set.sinks[0].sink.Send(Events("work", webhookNotifications[:1], nil))
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Expected no error, got %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr) {
+				t.Errorf("Expected error %q, got %v", tt.wantErr, err)
+			}
+			if len(recv.bodies) != tt.wantCalls {
+				t.Errorf("Expected %d requests, got %d", tt.wantCalls, len(recv.bodies))
+			}
+		})
+	}
+
+	t.Logf("✓ Webhook retry test passed!")
+}
+
+// TestRetryAfter tests Retry-After in seconds and as a date, capped
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 6, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", 0},
+		{"5", 5 * time.Second},
+		{"3600", webhookMaxRetryAfter},
+		{now.Add(10 * time.Second).Format(http.TimeFormat), 10 * time.Second},
+		{now.Add(-time.Minute).Format(http.TimeFormat), 0},
+		{"soon", 0},
+	}
+
+	for _, tt := range tests {
+		if got := retryAfter(tt.value, now); got != tt.want {
+			t.Errorf("retryAfter(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+
+	t.Logf("✓ Retry-After test passed!")
+}
+
+// TestWebhookSink_DiscordTruncation tests that long Discord messages are cut
+// on a character boundary
+func TestWebhookSink_DiscordTruncation(t *testing.T) {
+	sink, err := newWebhookSink(Config{URL: "http://localhost", Preset: PresetDiscord})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	notifications := []cache.CacheEntry{{Repository: "owner/repo", Reason: "mention", Title: strings.Repeat("é", 3000)}}
+	body, err := sink.payload(Events("", notifications, nil))
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	var payload map[string]string
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Expected a JSON body: %v", err)
+	}
+	content := payload["content"]
+	if !utf8.ValidString(content) || utf8.RuneCountInString(content) != discordMaxContent || !strings.HasSuffix(content, "é...") {
+		t.Errorf("Expected %d valid characters ending in an ellipsis, got %d", discordMaxContent, utf8.RuneCountInString(content))
+	}
+
+	t.Logf("✓ Discord truncation test passed!")
+}